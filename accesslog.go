@@ -0,0 +1,301 @@
+package oakhttp
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const redactedValue = "[REDACTED]"
+
+// DefaultRedactedHeaders are masked by the access log unless other headers are chosen with [WithAccessLogRedactedHeaders].
+var DefaultRedactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+}
+
+type accessLogOptions struct {
+	Logger                  *slog.Logger
+	SampleRate              float64
+	SkipPaths               []string
+	SlowThreshold           time.Duration
+	SlowLevel               slog.Level
+	LogHeaders              bool
+	RedactedHeaders         []string
+	RedactedQueryParameters []string
+}
+
+type AccessLogOption func(*accessLogOptions) error
+
+func WithAccessLogger(logger *slog.Logger) AccessLogOption {
+	return func(o *accessLogOptions) error {
+		if o.Logger != nil {
+			return errors.New("access logger is already set")
+		}
+		if logger == nil {
+			return errors.New("cannot use a <nil> structured logger")
+		}
+		o.Logger = logger
+		return nil
+	}
+}
+
+// WithAccessLogSampling logs only a fraction of requests. Failed and slow requests are always logged.
+func WithAccessLogSampling(rate float64) AccessLogOption {
+	return func(o *accessLogOptions) error {
+		if o.SampleRate != 0 {
+			return errors.New("access log sampling rate is already set")
+		}
+		if rate <= 0 || rate > 1 {
+			return errors.New("access log sampling rate must be greater than 0 and not exceed 1")
+		}
+		o.SampleRate = rate
+		return nil
+	}
+}
+
+// WithAccessLogSkipPaths excludes requests to the exact URL paths, such as health checks, from the access log.
+func WithAccessLogSkipPaths(paths ...string) AccessLogOption {
+	return func(o *accessLogOptions) error {
+		for _, path := range paths {
+			if path == "" {
+				return errors.New("cannot skip an empty path")
+			}
+			if slices.Contains(o.SkipPaths, path) {
+				return fmt.Errorf("path %q is already skipped", path)
+			}
+			o.SkipPaths = append(o.SkipPaths, path)
+		}
+		return nil
+	}
+}
+
+// WithAccessLogSlowRequests escalates requests that take longer than the threshold to the given level.
+func WithAccessLogSlowRequests(threshold time.Duration, level slog.Level) AccessLogOption {
+	return func(o *accessLogOptions) error {
+		if o.SlowThreshold != 0 {
+			return errors.New("slow request threshold is already set")
+		}
+		if threshold < time.Millisecond {
+			return errors.New("cannot set slow request threshold lower than 1ms")
+		}
+		o.SlowThreshold = threshold
+		o.SlowLevel = level
+		return nil
+	}
+}
+
+// WithAccessLogHeaders includes request headers in the access log. Sensitive headers are masked.
+func WithAccessLogHeaders() AccessLogOption {
+	return func(o *accessLogOptions) error {
+		if o.LogHeaders {
+			return errors.New("header logging is already enabled")
+		}
+		o.LogHeaders = true
+		return nil
+	}
+}
+
+// WithAccessLogRedactedHeaders masks the values of the named request headers. Replaces [DefaultRedactedHeaders].
+func WithAccessLogRedactedHeaders(names ...string) AccessLogOption {
+	return func(o *accessLogOptions) error {
+		if o.RedactedHeaders != nil {
+			return errors.New("redacted headers are already set")
+		}
+		if len(names) == 0 {
+			return errors.New("provide at least one header name to redact")
+		}
+		o.RedactedHeaders = make([]string, len(names))
+		for i, name := range names {
+			if name == "" {
+				return errors.New("cannot redact an empty header name")
+			}
+			o.RedactedHeaders[i] = http.CanonicalHeaderKey(name)
+		}
+		return nil
+	}
+}
+
+// WithAccessLogRedactedQueryParameters masks the values of the named URL query parameters, such as one-time tokens.
+func WithAccessLogRedactedQueryParameters(names ...string) AccessLogOption {
+	return func(o *accessLogOptions) error {
+		for _, name := range names {
+			if name == "" {
+				return errors.New("cannot redact an empty query parameter name")
+			}
+			if slices.Contains(o.RedactedQueryParameters, name) {
+				return fmt.Errorf("query parameter %q is already redacted", name)
+			}
+			o.RedactedQueryParameters = append(o.RedactedQueryParameters, name)
+		}
+		return nil
+	}
+}
+
+type accessLogHandler struct {
+	Next                    http.Handler
+	Logger                  *slog.Logger
+	SampleRate              float64
+	SkipPaths               []string
+	SlowThreshold           time.Duration
+	SlowLevel               slog.Level
+	LogHeaders              bool
+	RedactedHeaders         []string
+	RedactedQueryParameters []string
+}
+
+// NewAccessLogMiddleware logs every completed request with its status code, response size, and duration. Server errors are logged at [slog.LevelError]. Place it after [NewTraceContextMiddleware] so that the logger created with [NewTracingHandler] includes the trace ID.
+func NewAccessLogMiddleware(withOptions ...AccessLogOption) (Middleware, error) {
+	o := &accessLogOptions{}
+	var err error
+	for _, option := range append(
+		withOptions,
+		func(o *accessLogOptions) error { // defaults
+			if o.Logger == nil {
+				o.Logger = slog.Default()
+			}
+			if o.SampleRate == 0 {
+				o.SampleRate = 1
+			}
+			if o.RedactedHeaders == nil {
+				o.RedactedHeaders = DefaultRedactedHeaders
+			}
+			return nil
+		},
+	) {
+		if err = option(o); err != nil {
+			return nil, fmt.Errorf("cannot initialize access log middleware: %w", err)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		if next == nil {
+			panic("cannot use a <nil> handler")
+		}
+		return accessLogHandler{
+			Next:                    next,
+			Logger:                  o.Logger,
+			SampleRate:              o.SampleRate,
+			SkipPaths:               o.SkipPaths,
+			SlowThreshold:           o.SlowThreshold,
+			SlowLevel:               o.SlowLevel,
+			LogHeaders:              o.LogHeaders,
+			RedactedHeaders:         o.RedactedHeaders,
+			RedactedQueryParameters: o.RedactedQueryParameters,
+		}
+	}, nil
+}
+
+func (h accessLogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if slices.Contains(h.SkipPaths, r.URL.Path) {
+		h.Next.ServeHTTP(w, r)
+		return
+	}
+
+	rw := newResponseWriter(w)
+	start := time.Now()
+	defer func() {
+		if recovery := recover(); recovery != nil {
+			h.log(r, rw, time.Since(start), true)
+			panic(recovery)
+		}
+		h.log(r, rw, time.Since(start), false)
+	}()
+	h.Next.ServeHTTP(rw, r)
+}
+
+func (h accessLogHandler) log(r *http.Request, rw *responseWriter, duration time.Duration, panicked bool) {
+	statusCode := rw.StatusCode
+	if !rw.WroteHeader {
+		statusCode = http.StatusOK
+	}
+	if panicked && !rw.WroteHeader {
+		statusCode = http.StatusInternalServerError
+	}
+
+	level := slog.LevelInfo
+	slow := h.SlowThreshold > 0 && duration >= h.SlowThreshold
+	switch {
+	case panicked || statusCode >= 500:
+		level = slog.LevelError
+	case slow:
+		level = h.SlowLevel
+	case statusCode >= 400:
+		// client errors are never sampled away
+	case h.SampleRate < 1 && rand.Float64() >= h.SampleRate:
+		return
+	}
+
+	ctx := r.Context()
+	if !h.Logger.Enabled(ctx, level) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", r.Method),
+		slog.String("host", r.Host),
+		slog.String("path", r.URL.Path),
+		slog.Int("status_code", statusCode),
+		slog.Int64("bytes_written", rw.BytesWritten),
+		slog.Duration("duration", duration),
+		slog.String("remote_address", r.RemoteAddr),
+		slog.String("protocol", r.Proto),
+	}
+	if client, ok := ClientAddressFromContext(ctx); ok && client.IP.IsValid() {
+		attrs = append(attrs, slog.String("client_address", client.IP.String()))
+	}
+	if r.URL.RawQuery != "" {
+		attrs = append(attrs, slog.String("query", h.redactQuery(r.URL.RawQuery)))
+	}
+	if userAgent := r.UserAgent(); userAgent != "" {
+		attrs = append(attrs, slog.String("user_agent", userAgent))
+	}
+	if slow {
+		attrs = append(attrs, slog.Bool("slow", true))
+	}
+	if panicked {
+		attrs = append(attrs, slog.Bool("panicked", true))
+	}
+	if h.LogHeaders {
+		attrs = append(attrs, slog.Any("headers", h.redactHeaders(r.Header)))
+	}
+	h.Logger.LogAttrs(ctx, level, "HTTP request", attrs...)
+}
+
+func (h accessLogHandler) redactQuery(rawQuery string) string {
+	if len(h.RedactedQueryParameters) == 0 {
+		return rawQuery
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return redactedValue // cannot tell which parts are sensitive
+	}
+	for _, name := range h.RedactedQueryParameters {
+		if values, ok := query[name]; ok {
+			for i := range values {
+				values[i] = redactedValue
+			}
+		}
+	}
+	return query.Encode()
+}
+
+func (h accessLogHandler) redactHeaders(header http.Header) slog.Value {
+	attrs := make([]slog.Attr, 0, len(header))
+	for _, name := range slices.Sorted(maps.Keys(header)) {
+		value := strings.Join(header.Values(name), ", ")
+		if slices.Contains(h.RedactedHeaders, name) {
+			value = redactedValue
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,123 @@
+package oakhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	b := &bytes.Buffer{}
+	mw, err := NewAccessLogMiddleware(
+		WithAccessLogger(slog.New(NewTracingHandler(slog.NewJSONHandler(b, nil)))),
+		WithAccessLogSkipPaths("/health"),
+		WithAccessLogSlowRequests(time.Millisecond*20, slog.LevelWarn),
+		WithAccessLogHeaders(),
+		WithAccessLogRedactedQueryParameters("token"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewTraceContextMiddleware()(mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(time.Millisecond * 25)
+		case "/fail":
+			w.WriteHeader(http.StatusBadGateway)
+		}
+		_, _ = w.Write([]byte("test"))
+	})))
+
+	type entry struct {
+		Level        string            `json:"level"`
+		TraceID      string            `json:"traceID"`
+		Path         string            `json:"path"`
+		Query        string            `json:"query"`
+		StatusCode   int               `json:"status_code"`
+		BytesWritten int64             `json:"bytes_written"`
+		Slow         bool              `json:"slow"`
+		Headers      map[string]string `json:"headers"`
+	}
+	serve := func(t *testing.T, target string) (e entry, logged bool) {
+		b.Reset()
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Header.Set("Authorization", "Bearer secret")
+		r.Header.Set("X-Test", "visible")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		if b.Len() == 0 {
+			return e, false
+		}
+		if err := json.Unmarshal(b.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		return e, true
+	}
+
+	t.Run("success", func(t *testing.T) {
+		e, logged := serve(t, "/page?token=secret&page=2")
+		if !logged {
+			t.Fatal("request was not logged")
+		}
+		if e.Level != "INFO" || e.StatusCode != http.StatusOK || e.BytesWritten != 4 {
+			t.Fatalf("unexpected log entry: %+v", e)
+		}
+		if e.TraceID == "" {
+			t.Fatal("trace ID is missing")
+		}
+		if e.Query != "page=2&token=%5BREDACTED%5D" {
+			t.Fatal("query parameter was not redacted:", e.Query)
+		}
+		if e.Headers["Authorization"] != redactedValue || e.Headers["X-Test"] != "visible" {
+			t.Fatal("headers were not redacted correctly:", e.Headers)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		e, _ := serve(t, "/fail")
+		if e.Level != "ERROR" || e.StatusCode != http.StatusBadGateway {
+			t.Fatalf("unexpected log entry: %+v", e)
+		}
+	})
+
+	t.Run("slow", func(t *testing.T) {
+		e, _ := serve(t, "/slow")
+		if e.Level != "WARN" || !e.Slow {
+			t.Fatalf("unexpected log entry: %+v", e)
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		if _, logged := serve(t, "/health"); logged {
+			t.Fatal("skipped path was logged")
+		}
+	})
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	b := &bytes.Buffer{}
+	mw, err := NewAccessLogMiddleware(
+		WithAccessLogger(slog.New(slog.NewJSONHandler(b, nil))),
+		WithAccessLogSampling(0.000001),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if b.Len() != 0 {
+		t.Fatal("successful request was not sampled away")
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if b.Len() == 0 {
+		t.Fatal("failed request was sampled away")
+	}
+}
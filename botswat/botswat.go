@@ -3,7 +3,6 @@ package botswat
 import (
 	"errors"
 	"fmt"
-	"net"
 	"net/http"
 
 	"github.com/dkotik/oakhttp"
@@ -24,7 +23,10 @@ func (b *botswat) IsHuman(r *http.Request) error {
 	if token == "" {
 		return ErrTokenEmpty
 	}
-	ip, _, _ := net.SplitHostPort(r.RemoteAddr)
+	var ip string // verifiers omit the address when it is unknown
+	if address := oakhttp.ClientIP(r); address.IsValid() {
+		ip = address.String()
+	}
 	// first returned value is data
 	_, err = b.Verifier.VerifyHumanityToken(r.Context(), token, ip)
 	return err
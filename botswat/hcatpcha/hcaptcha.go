@@ -2,14 +2,14 @@ package hcaptcha
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"sync"
 	"time"
+
+	"github.com/dkotik/oakhttp/client"
 )
 
 // TODO: model all errors: https://docs.hcaptcha.com/#siteverify-error-codes-table
@@ -69,6 +69,10 @@ func (h *HCaptchaError) Error() string {
 	return fmt.Sprintf("HCaptcha humanity validation failed for site key %q: %v", h.SiteKey, h.Cause)
 }
 
+func (h *HCaptchaError) Unwrap() error {
+	return h.Cause
+}
+
 func NewMemoryCachedValidator(d time.Duration, v HCaptchaValidator) HCaptchaValidator {
 	cache := make(map[string]time.Time)
 	mu := &sync.Mutex{}
@@ -121,8 +125,28 @@ func NewMemoryCachedValidator(d time.Duration, v HCaptchaValidator) HCaptchaVali
 	}
 }
 
-func NewHCaptchaValidator(siteKey, secretKey string) HCaptchaValidator {
-	client := http.DefaultClient // TODO: harden
+// NewHCaptchaValidator verifies tokens issued to the site key. The secret key is read from HCAPTCHA_SECRET_KEY environment variable unless set using [WithSecret]. Response host name is checked only if set using [WithHostname] or HCAPTCHA_HOST_NAME environment variable.
+func NewHCaptchaValidator(siteKey string, withOptions ...Option) (HCaptchaValidator, error) {
+	if siteKey == "" {
+		return nil, errors.New("cannot use an empty site key")
+	}
+	o := &options{}
+	for _, option := range append(withOptions, WithDefaultOptions()) {
+		if err := option(o); err != nil {
+			return nil, fmt.Errorf("cannot initialize HCaptcha validator: %w", err)
+		}
+	}
+	endpoint, err := client.NewEndpoint[url.Values, *Response, *Response](
+		http.MethodPost,
+		o.Endpoint,
+		client.WithEndpointClient(o.HTTPClient),
+		client.WithEndpointResponseLimit(responseReadLimit),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize HCaptcha validator: %w", err)
+	}
+	secretKey := o.Secret
+	hostname := o.Hostname
 
 	return func(ctx context.Context, token, personIP string) (err error) {
 		defer func() {
@@ -142,34 +166,21 @@ func NewHCaptchaValidator(siteKey, secretKey string) HCaptchaValidator {
 		// 	return nil // TODO: REMOVE passthrough
 		// }
 
-		raw, err := client.PostForm(
-			"hCaptchaAPIEndpoint", url.Values{ // TODO: fix variable.
-				"secret":   {secretKey},
-				"sitekey":  {siteKey},
-				"remoteip": {personIP},
-				"response": {token},
-			})
+		response, err := endpoint.Call(ctx, url.Values{
+			"secret":   {secretKey},
+			"sitekey":  {siteKey},
+			"remoteip": {personIP},
+			"response": {token},
+		})
 		if err != nil {
-			return fmt.Errorf("failed to reach HCaptcha server: %w", err)
-		}
-
-		body, err := io.ReadAll(raw.Body)
-		raw.Body.Close()
-		if err != nil {
-			return fmt.Errorf("failed to read HCaptcha response body: %w", err)
-		}
-
-		var response struct {
-			ChallengeTS string   `json:"challenge_ts"`
-			Hostname    string   `json:"hostname"`
-			ErrorCodes  []string `json:"error-codes,omitempty"`
-			Success     bool     `json:"success"`
-			Credit      bool     `json:"credit,omitempty"`
+			var apiErr *client.APIError[*Response]
+			if errors.As(err, &apiErr) && apiErr.Failure != nil && len(apiErr.Failure.ErrorCodes) > 0 {
+				return fmt.Errorf("error codes: %+v", apiErr.Failure.ErrorCodes)
+			}
+			return fmt.Errorf("HCaptcha request failed: %w", err)
 		}
-
-		err = json.Unmarshal(body, &response)
-		if err != nil {
-			return fmt.Errorf("failed to parse HCaptcha response body: %w", err)
+		if response == nil {
+			return errors.New("empty response")
 		}
 
 		if len(response.ErrorCodes) > 0 {
@@ -180,8 +191,11 @@ func NewHCaptchaValidator(siteKey, secretKey string) HCaptchaValidator {
 			return errors.New("unknown cause")
 		}
 
+		if hostname != "" && response.Hostname != hostname {
+			return fmt.Errorf("host name %q does not match %q", response.Hostname, hostname)
+		}
 		return nil
-	}
+	}, nil
 }
 
 /*
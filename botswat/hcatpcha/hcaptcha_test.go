@@ -0,0 +1,84 @@
+package hcaptcha
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dkotik/oakhttp/client"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("secret") != "secret" || r.FormValue("sitekey") != "site" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.FormValue("response") {
+		case "valid":
+			_, _ = w.Write([]byte(`{"success":true,"hostname":"example.com"}`))
+		case "elsewhere":
+			_, _ = w.Write([]byte(`{"success":true,"hostname":"attacker.com"}`))
+		case "expired":
+			_, _ = w.Write([]byte(`{"success":false,"error-codes":["invalid-or-already-seen-response"]}`))
+		case "rejected":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"success":false,"error-codes":["bad-request"]}`))
+		case "large":
+			_, _ = w.Write([]byte(`{"success":true,"hostname":"` + strings.Repeat("a", responseReadLimit) + `"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHCaptchaValidator(t *testing.T) {
+	server := newTestServer(t)
+	validate, err := NewHCaptchaValidator(
+		"site",
+		WithSecret("secret"),
+		WithEndpoint(server.URL),
+		WithHTTPClient(server.Client()),
+		WithHostname("example.com"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err = validate(ctx, "valid", "203.0.113.7"); err != nil {
+		t.Fatal("valid token was rejected:", err)
+	}
+	for _, token := range []string{"elsewhere", "expired", "rejected", "large", "broken"} {
+		err = validate(ctx, token, "203.0.113.7")
+		var hErr *HCaptchaError
+		if !errors.As(err, &hErr) || hErr.SiteKey != "site" {
+			t.Errorf("token %q was not rejected with an HCaptcha error: %v", token, err)
+		}
+	}
+	if err = validate(ctx, "expired", ""); !strings.Contains(err.Error(), "invalid-or-already-seen-response") {
+		t.Error("error codes were not reported:", err)
+	}
+	if err = validate(ctx, "rejected", ""); !strings.Contains(err.Error(), "bad-request") {
+		t.Error("error codes of a failed request were not reported:", err)
+	}
+	if err = validate(ctx, "large", ""); !errors.Is(err, client.ErrResponseBodyTooLarge) {
+		t.Error("oversized response was not rejected:", err)
+	}
+}
+
+func TestNewHCaptchaValidatorFailsEarly(t *testing.T) {
+	if _, err := NewHCaptchaValidator("site", WithSecret("secret"), WithEndpoint("/relative")); err == nil {
+		t.Fatal("invalid endpoint was accepted")
+	}
+	if _, err := NewHCaptchaValidator("", WithSecret("secret")); err == nil {
+		t.Fatal("empty site key was accepted")
+	}
+}
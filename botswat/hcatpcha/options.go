@@ -28,11 +28,11 @@ type Option func(*options) error
 func WithDefaultOptions() Option {
 	return func(o *options) (err error) {
 		if o.HTTPClient == nil {
-			client, err := client.New()
+			httpClient, err := client.New()
 			if err != nil {
 				return err
 			}
-			if err = WithHTTPClient(client)(o); err != nil {
+			if err = WithHTTPClient(httpClient)(o); err != nil {
 				return err
 			}
 		}
@@ -43,15 +43,15 @@ func WithDefaultOptions() Option {
 				return fmt.Errorf("please check HCAPTCHA_SECRET_KEY environment variable: %w", err)
 			}
 		}
-		if o.Secret == "" {
-			if err = WithHostname(
-				os.Getenv("HCAPTCHA_HOST_NAME"),
-			)(o); err != nil {
-				return fmt.Errorf("please check HCAPTCHA_HOST_NAME environment variable: %w", err)
+		if o.Hostname == "" {
+			if hostname := os.Getenv("HCAPTCHA_HOST_NAME"); hostname != "" {
+				if err = WithHostname(hostname)(o); err != nil {
+					return err
+				}
 			}
 		}
 		if o.Endpoint == "" {
-			if err = WithEndpoint("https://hcaptcha.com/siteverify")(o); err != nil {
+			if err = WithEndpoint(DefaultEndpoint)(o); err != nil {
 				return err
 			}
 		}
@@ -59,15 +59,15 @@ func WithDefaultOptions() Option {
 	}
 }
 
-func WithHTTPClient(client *http.Client) Option {
+func WithHTTPClient(httpClient *http.Client) Option {
 	return func(o *options) error {
 		if o.HTTPClient != nil {
 			return errors.New("HTTP client is already set")
 		}
-		if client == nil {
+		if httpClient == nil {
 			return errors.New("cannot use a <nil> HTTP client")
 		}
-		o.HTTPClient = client
+		o.HTTPClient = httpClient
 		return nil
 	}
 }
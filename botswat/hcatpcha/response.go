@@ -1 +1,28 @@
 package hcaptcha
+
+const (
+	// DefaultEndpoint verifies tokens issued to visitors.
+	DefaultEndpoint = "https://api.hcaptcha.com/siteverify"
+
+	responseReadLimit = 1024 * 24
+)
+
+// Response is returned by the verification endpoint.
+//
+// https://docs.hcaptcha.com/#verify-the-user-response-server-side
+type Response struct {
+	// Success is true if the token is valid and was issued to the site key.
+	Success bool `json:"success"`
+
+	// ChallengeTime when the challenge was solved in ISO 8601 format.
+	ChallengeTime string `json:"challenge_ts"`
+
+	// Hostname of the site where the challenge was solved.
+	Hostname string `json:"hostname"`
+
+	// Credit is true if the response will be credited.
+	Credit bool `json:"credit,omitempty"`
+
+	// ErrorCodes of any problems that were encountered.
+	ErrorCodes []string `json:"error-codes,omitempty"`
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/dkotik/oakhttp/client"
 )
 
 const DefaultRetention = time.Hour * 24 * 7
@@ -22,15 +24,15 @@ type (
 	Option func(*options) error
 )
 
-func WithHTTPClient(client *http.Client) Option {
+func WithHTTPClient(httpClient *http.Client) Option {
 	return func(o *options) error {
 		if o.HTTPClient != nil {
 			return errors.New("HTTP client is already set")
 		}
-		if client == nil {
+		if httpClient == nil {
 			return errors.New("cannot use a <nil> HTTP client")
 		}
-		o.HTTPClient = client
+		o.HTTPClient = httpClient
 		return nil
 	}
 }
@@ -40,9 +42,11 @@ func WithDefaultHTTPClient() Option {
 		if o.HTTPClient != nil {
 			return nil
 		}
-		return WithHTTPClient(&http.Client{
-			Timeout: time.Second * 2,
-		})(o)
+		httpClient, err := client.New(client.WithTimeout(time.Second * 2))
+		if err != nil {
+			return err
+		}
+		return WithHTTPClient(httpClient)(o)
 	}
 }
 
@@ -11,18 +11,17 @@ Cloudflare Turnstile documentation: <https://developers.cloudflare.com/turnstile
 package turnstile
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+
+	"github.com/dkotik/oakhttp/client"
 )
 
 type Turnstile struct {
-	client    *http.Client
+	endpoint  *client.Endpoint[*Request, *Response, *Response]
 	secretKey string
-	endpoint  string
 	hostname  string
 }
 
@@ -35,36 +34,27 @@ func (t *Turnstile) Challenge(
 	userData string,
 	err error,
 ) {
-	payload, err := json.Marshal(&Request{
+	r, err := t.endpoint.Call(ctx, &Request{
 		Secret:   t.secretKey,
 		Response: clientResponseToken,
 		RemoteIP: clientIPAddress,
 	})
 	if err != nil {
-		return "", fmt.Errorf("JSON encoding failed: %w", err)
-	}
-
-	request, err := http.NewRequest("POST", t.endpoint, bytes.NewReader(payload))
-	if err != nil {
-		return "", fmt.Errorf("invalid HTTP API request: %w", err)
-	}
-	request = request.WithContext(ctx)
-	request.Header.Set("Content-Type", "application/json") // critical
-	hr, err := t.client.Do(request)
-	if err != nil {
+		var apiErr *client.APIError[*Response]
+		if errors.As(err, &apiErr) && apiErr.Failure != nil && len(apiErr.Failure.ErrorCodes) > 0 {
+			return "", fmt.Errorf("turnstile request failed: %w", apiErr.Failure.Validate())
+		}
 		return "", fmt.Errorf("HTTP API request failed: %w", err)
 	}
-	defer hr.Body.Close()
-
-	var r *Response
-	if err = json.NewDecoder(
-		io.LimitReader(hr.Body, responseReadLimit),
-	).Decode(&r); err != nil {
-		return "", fmt.Errorf("JSON decoding failure: %w", err)
+	if r == nil {
+		return "", errors.New("turnstile returned an empty response")
 	}
 	if err = r.Validate(); err != nil {
 		return "", fmt.Errorf("turnstile request failed: %w", err)
 	}
+	if !r.Success {
+		return "", fmt.Errorf("turnstile request failed: %w", ErrInternalError)
+	}
 
 	if r.Hostname != t.hostname {
 		return "", fmt.Errorf("hostnames %q does not match %q", r.Hostname, t.hostname)
@@ -93,10 +83,19 @@ func New(withOptions ...Option) (*Turnstile, error) {
 		}
 	}
 
+	endpoint, err := client.NewEndpoint[*Request, *Response, *Response](
+		http.MethodPost,
+		o.Endpoint,
+		client.WithEndpointClient(o.HTTPClient),
+		client.WithEndpointResponseLimit(responseReadLimit),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize Cloudflare Turnstile verifier: %w", err)
+	}
+
 	return &Turnstile{
-		client:    o.HTTPClient,
+		endpoint:  endpoint,
 		secretKey: o.SecretKey,
-		endpoint:  o.Endpoint,
 		hostname:  o.Hostname,
 	}, nil
 }
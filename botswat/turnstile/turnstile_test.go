@@ -0,0 +1,92 @@
+package turnstile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dkotik/oakhttp/client"
+)
+
+func TestChallenge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request Request
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Secret != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch request.Response {
+		case "valid":
+			_, _ = w.Write([]byte(`{"success":true,"hostname":"example.com","action":"login","cdata":"data"}`))
+		case "elsewhere":
+			_, _ = w.Write([]byte(`{"success":true,"hostname":"attacker.com","action":"login"}`))
+		case "expired":
+			_, _ = w.Write([]byte(`{"success":false,"error-codes":["timeout-or-duplicate"]}`))
+		case "unexplained":
+			_, _ = w.Write([]byte(`{"success":false}`))
+		case "rejected":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"success":false,"error-codes":["bad-request"]}`))
+		case "large":
+			_, _ = w.Write([]byte(`{"success":true,"cdata":"` + strings.Repeat("a", responseReadLimit) + `"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	verifier, err := New(
+		WithHTTPClient(server.Client()),
+		WithEndpoint(server.URL),
+		WithSecretKey("secret"),
+		WithHostname("example.com"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	challenge := func(token string) error {
+		_, err := verifier.Challenge(ctx, token, "203.0.113.7", "login")
+		return err
+	}
+
+	userData, err := verifier.Challenge(ctx, "valid", "203.0.113.7", "login")
+	if err != nil {
+		t.Fatal("valid token was rejected:", err)
+	}
+	if userData != "data" {
+		t.Fatal("unexpected user data:", userData)
+	}
+	if _, err = verifier.Challenge(ctx, "valid", "203.0.113.7", "signup"); err == nil {
+		t.Error("mismatched action was accepted")
+	}
+	for _, token := range []string{"elsewhere", "unexplained", "broken"} {
+		if err = challenge(token); err == nil {
+			t.Errorf("token %q was accepted", token)
+		}
+	}
+	if err = challenge("expired"); !errors.Is(err, ErrTimeoutOrDuplicate) {
+		t.Error("error code was not reported:", err)
+	}
+	if err = challenge("rejected"); !errors.Is(err, ErrBadRequest) {
+		t.Error("error code of a failed request was not reported:", err)
+	}
+	if err = challenge("large"); !errors.Is(err, client.ErrResponseBodyTooLarge) {
+		t.Error("oversized response was not rejected:", err)
+	}
+}
+
+func TestNewFailsEarly(t *testing.T) {
+	if _, err := New(
+		WithEndpoint("/relative"),
+		WithSecretKey("secret"),
+		WithHostname("example.com"),
+	); err == nil {
+		t.Fatal("invalid endpoint was accepted")
+	}
+}
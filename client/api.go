@@ -0,0 +1,275 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const DefaultEndpointResponseLimit = 1 << 20
+
+// APIError is returned by [Endpoint.Call] for responses with status codes outside of the 2xx range.
+type APIError[F any] struct {
+	StatusCode int
+	// Failure is decoded from the response body. It is the zero value if the body could not be decoded.
+	Failure F
+	// Err explains why the response body could not be decoded.
+	Err error
+}
+
+func (e *APIError[F]) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("API responded with status code %d: %v", e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("API responded with status code %d", e.StatusCode)
+}
+
+func (e *APIError[F]) Unwrap() error {
+	return e.Err
+}
+
+type endpointOptions struct {
+	Client        *http.Client
+	ResponseLimit int64
+	Timeout       time.Duration
+	Header        http.Header
+}
+
+type EndpointOption func(*endpointOptions) error
+
+// WithEndpointClient sends requests using the client instead of the one created by [New] with default settings.
+func WithEndpointClient(client *http.Client) EndpointOption {
+	return func(o *endpointOptions) error {
+		if o.Client != nil {
+			return errors.New("HTTP client is already set")
+		}
+		if client == nil {
+			return errors.New("cannot use a <nil> HTTP client")
+		}
+		o.Client = client
+		return nil
+	}
+}
+
+// WithEndpointResponseLimit fails calls with [ResponseBodyTooLargeError] when the response body exceeds the limit. Defaults to [DefaultEndpointResponseLimit].
+func WithEndpointResponseLimit(limit int64) EndpointOption {
+	return func(o *endpointOptions) error {
+		if o.ResponseLimit != 0 {
+			return errors.New("response limit is already set")
+		}
+		if limit < 1 {
+			return errors.New("response limit must be greater than 0")
+		}
+		o.ResponseLimit = limit
+		return nil
+	}
+}
+
+// WithEndpointTimeout sets a deadline for each call, unless the call context expires sooner.
+func WithEndpointTimeout(d time.Duration) EndpointOption {
+	return func(o *endpointOptions) error {
+		if o.Timeout != 0 {
+			return errors.New("timeout is already set")
+		}
+		if d < time.Millisecond*10 {
+			return errors.New("timeout must be at least 10ms")
+		}
+		if d > time.Minute*5 {
+			return errors.New("timeout must be less than five minutes")
+		}
+		o.Timeout = d
+		return nil
+	}
+}
+
+// WithEndpointHeader adds a header to every request, such as an API key.
+func WithEndpointHeader(name, value string) EndpointOption {
+	return func(o *endpointOptions) error {
+		if name == "" {
+			return errors.New("cannot use an empty header name")
+		}
+		if o.Header == nil {
+			o.Header = make(http.Header)
+		}
+		o.Header.Add(name, value)
+		return nil
+	}
+}
+
+// Endpoint calls a JSON API method with typed requests and responses. Error responses are decoded into [APIError] with the Failure type.
+//
+// Requests are encoded as JSON, except for [url.Values], which are encoded as a form or, for methods that do not carry a body, as a URL query. Use struct{} as Request type for calls without parameters.
+type Endpoint[Request, Response, Failure any] struct {
+	client  *http.Client
+	method  string
+	url     *url.URL
+	limit   int64
+	timeout time.Duration
+	header  http.Header
+}
+
+// NewEndpoint prepares calls to an HTTP or HTTPS URL.
+//
+//	type Greeting struct { Message string `json:"message"` }
+//	type Problem struct { Detail string `json:"detail"` }
+//
+//	greet, err := client.NewEndpoint[url.Values, Greeting, Problem](
+//	  http.MethodGet, "https://api.example.com/greeting")
+//	if err != nil {
+//	  return err
+//	}
+//	greeting, err := greet.Call(ctx, url.Values{"name": {"Oak"}})
+//	var problem *client.APIError[Problem]
+//	if errors.As(err, &problem) {
+//	  return fmt.Errorf("greeting failed: %s", problem.Failure.Detail)
+//	}
+func NewEndpoint[Request, Response, Failure any](method, endpointURL string, withOptions ...EndpointOption) (*Endpoint[Request, Response, Failure], error) {
+	o := &endpointOptions{}
+	var err error
+	for _, option := range append(
+		withOptions,
+		func(o *endpointOptions) error { // defaults
+			if o.Client == nil {
+				if o.Client, err = New(); err != nil {
+					return err
+				}
+			}
+			if o.ResponseLimit == 0 {
+				o.ResponseLimit = DefaultEndpointResponseLimit
+			}
+			return nil
+		},
+	) {
+		if err = option(o); err != nil {
+			return nil, fmt.Errorf("cannot initialize API endpoint: %w", err)
+		}
+	}
+
+	if method == "" || strings.ContainsAny(method, " \t\r\n") {
+		return nil, fmt.Errorf("invalid HTTP method %q", method)
+	}
+	u, err := url.Parse(endpointURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint URL: %w", err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("endpoint URL %q must be absolute with HTTP or HTTPS scheme", u.Redacted())
+	}
+	return &Endpoint[Request, Response, Failure]{
+		client:  o.Client,
+		method:  method,
+		url:     u,
+		limit:   o.ResponseLimit,
+		timeout: o.Timeout,
+		header:  o.Header,
+	}, nil
+}
+
+func (e *Endpoint[Request, Response, Failure]) newRequest(ctx context.Context, request Request) (*http.Request, error) {
+	u := *e.url
+	var (
+		body        io.Reader
+		contentType string
+	)
+	switch v := any(request).(type) {
+	case struct{}:
+	case url.Values:
+		switch e.method {
+		case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+			query := u.Query()
+			for key, values := range v {
+				query[key] = append(query[key], values...)
+			}
+			u.RawQuery = query.Encode()
+		default:
+			body = strings.NewReader(v.Encode())
+			contentType = "application/x-www-form-urlencoded"
+		}
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode API request: %w", err)
+		}
+		body = bytes.NewReader(b)
+		contentType = "application/json"
+	}
+
+	r, err := http.NewRequestWithContext(ctx, e.method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API request: %w", err)
+	}
+	for name, values := range e.header {
+		r.Header[name] = append([]string(nil), values...)
+	}
+	r.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r, nil
+}
+
+// Call sends the request and decodes the response. Responses with status codes outside of the 2xx range return [APIError] with the decoded Failure.
+func (e *Endpoint[Request, Response, Failure]) Call(ctx context.Context, request Request) (response Response, err error) {
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+	r, err := e.newRequest(ctx, request)
+	if err != nil {
+		return response, err
+	}
+	hr, err := e.client.Do(r)
+	if err != nil {
+		return response, fmt.Errorf("API request failed: %w", err)
+	}
+	defer DrainAndClose(hr.Body)
+	if hr.ContentLength > e.limit {
+		return response, &ResponseBodyTooLargeError{Limit: e.limit}
+	}
+	body := &limitedResponseBody{
+		ReadCloser: hr.Body,
+		limit:      e.limit,
+		remaining:  e.limit,
+	}
+
+	if hr.StatusCode < 200 || hr.StatusCode > 299 {
+		failure := &APIError[Failure]{StatusCode: hr.StatusCode}
+		failure.Err = decodeJSONResponse(hr, body, &failure.Failure)
+		return response, failure
+	}
+	if hr.StatusCode == http.StatusNoContent || e.method == http.MethodHead {
+		return response, nil
+	}
+	if err = decodeJSONResponse(hr, body, &response); err != nil {
+		return response, fmt.Errorf("cannot decode API response: %w", err)
+	}
+	return response, nil
+}
+
+func decodeJSONResponse(hr *http.Response, body io.Reader, v any) error {
+	contentType := hr.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+		return fmt.Errorf("unexpected response content type %q", contentType)
+	}
+	decoder := json.NewDecoder(body)
+	if err = decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err = decoder.Token(); !errors.Is(err, io.EOF) {
+		if err == nil {
+			return errors.New("response contains more than one JSON value")
+		}
+		return err
+	}
+	return nil
+}
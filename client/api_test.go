@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testGreeting struct {
+	Message string `json:"message"`
+}
+
+type testProblem struct {
+	Detail string `json:"detail"`
+}
+
+func TestEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("X-API-Key") != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			var request testGreeting
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_ = json.NewEncoder(w).Encode(testGreeting{Message: "hello " + request.Message})
+		case "/form":
+			if err := r.ParseForm(); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(testGreeting{Message: r.Method + " " + r.Form.Get("name")})
+		case "/problem":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"detail":"name is required"}`))
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html></html>`))
+		case "/large":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"message":"` + strings.Repeat("a", 128) + `"}`))
+		case "/slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Millisecond * 200):
+			}
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	t.Run("json", func(t *testing.T) {
+		endpoint, err := NewEndpoint[testGreeting, testGreeting, testProblem](
+			http.MethodPost, server.URL+"/json", WithEndpointHeader("X-API-Key", "secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		response, err := endpoint.Call(ctx, testGreeting{Message: "world"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.Message != "hello world" {
+			t.Fatal("unexpected response:", response.Message)
+		}
+	})
+
+	t.Run("form", func(t *testing.T) {
+		for _, method := range []string{http.MethodGet, http.MethodPost} {
+			endpoint, err := NewEndpoint[url.Values, testGreeting, testProblem](method, server.URL+"/form")
+			if err != nil {
+				t.Fatal(err)
+			}
+			response, err := endpoint.Call(ctx, url.Values{"name": {"oak"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.Message != method+" oak" {
+				t.Fatal("unexpected response:", response.Message)
+			}
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		endpoint, err := NewEndpoint[struct{}, testGreeting, testProblem](http.MethodGet, server.URL+"/problem")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = endpoint.Call(ctx, struct{}{})
+		var apiErr *APIError[testProblem]
+		if !errors.As(err, &apiErr) {
+			t.Fatal("expected an API error:", err)
+		}
+		if apiErr.StatusCode != http.StatusUnprocessableEntity || apiErr.Failure.Detail != "name is required" || apiErr.Err != nil {
+			t.Fatalf("unexpected API error: %+v", apiErr)
+		}
+
+		endpoint, err = NewEndpoint[struct{}, testGreeting, testProblem](http.MethodGet, server.URL+"/missing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = endpoint.Call(ctx, struct{}{})
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Err == nil {
+			t.Fatal("expected an API error with an undecodable body:", err)
+		}
+	})
+
+	t.Run("content type", func(t *testing.T) {
+		endpoint, err := NewEndpoint[struct{}, testGreeting, testProblem](http.MethodGet, server.URL+"/html")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = endpoint.Call(ctx, struct{}{}); err == nil || !strings.Contains(err.Error(), "content type") {
+			t.Fatal("HTML response was accepted:", err)
+		}
+	})
+
+	t.Run("response limit", func(t *testing.T) {
+		endpoint, err := NewEndpoint[struct{}, testGreeting, testProblem](
+			http.MethodGet, server.URL+"/large", WithEndpointResponseLimit(64))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = endpoint.Call(ctx, struct{}{}); !errors.Is(err, ErrResponseBodyTooLarge) {
+			t.Fatal("response limit was not enforced:", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		endpoint, err := NewEndpoint[struct{}, testGreeting, testProblem](
+			http.MethodGet, server.URL+"/slow", WithEndpointTimeout(time.Millisecond*20))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = endpoint.Call(ctx, struct{}{}); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatal("deadline was not enforced:", err)
+		}
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		endpoint, err := NewEndpoint[struct{}, *testGreeting, testProblem](http.MethodDelete, server.URL+"/empty")
+		if err != nil {
+			t.Fatal(err)
+		}
+		response, err := endpoint.Call(ctx, struct{}{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response != nil {
+			t.Fatal("expected an empty response:", response)
+		}
+	})
+}
+
+func TestNewEndpointValidation(t *testing.T) {
+	for _, endpointURL := range []string{"", "/relative", "ftp://example.com/file"} {
+		if _, err := NewEndpoint[struct{}, struct{}, struct{}](http.MethodGet, endpointURL); err == nil {
+			t.Errorf("endpoint URL %q was accepted", endpointURL)
+		}
+	}
+	if _, err := NewEndpoint[struct{}, struct{}, struct{}]("GET /", "https://example.com"); err == nil {
+		t.Error("invalid method was accepted")
+	}
+	if _, err := NewEndpoint[struct{}, struct{}, struct{}](
+		http.MethodGet, "https://example.com", WithEndpointClient(nil)); err == nil {
+		t.Error("nil client was accepted")
+	}
+}
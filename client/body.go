@@ -0,0 +1,202 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// MaximumDrainBytes is how much of a response body [DrainAndClose] reads before giving up on reusing the connection.
+const MaximumDrainBytes = 1 << 16
+
+// ErrResponseBodyTooLarge matches any [ResponseBodyTooLargeError] using [errors.Is].
+var ErrResponseBodyTooLarge = errors.New("response body is too large")
+
+// ResponseBodyTooLargeError is returned when a response body exceeds the limit set by [WithMaxResponseBodySize].
+type ResponseBodyTooLargeError struct {
+	Limit int64
+}
+
+func (e *ResponseBodyTooLargeError) Error() string {
+	return fmt.Sprintf("response body exceeds the limit of %d bytes", e.Limit)
+}
+
+func (e *ResponseBodyTooLargeError) Is(target error) bool {
+	return target == ErrResponseBodyTooLarge
+}
+
+// DrainAndClose reads the rest of a response body and closes it, so that the connection can be reused for the next request. Bodies longer than [MaximumDrainBytes] are closed without reading them to the end.
+//
+//	response, err := client.Do(request)
+//	if err != nil {
+//	  return err
+//	}
+//	defer client.DrainAndClose(response.Body)
+func DrainAndClose(body io.ReadCloser) error {
+	if body == nil {
+		return nil
+	}
+	_, err := io.CopyN(io.Discard, body, MaximumDrainBytes)
+	if errors.Is(err, io.EOF) || errors.Is(err, ErrResponseBodyTooLarge) {
+		err = nil
+	}
+	return errors.Join(err, body.Close())
+}
+
+// WithMaxResponseBodySize fails requests with a declared Content-Length above the limit and response body reads past the limit with [ResponseBodyTooLargeError] using [NewBodyLimitTransport].
+func WithMaxResponseBodySize(limit int64) Option {
+	return func(o *options) error {
+		if o.MaxResponseBodySize != 0 {
+			return errors.New("maximum response body size is already set")
+		}
+		if limit < 1 {
+			return errors.New("maximum response body size must be greater than 0")
+		}
+		o.MaxResponseBodySize = limit
+		return nil
+	}
+}
+
+type bodyLimitTransport struct {
+	next  http.RoundTripper
+	limit int64
+}
+
+// NewBodyLimitTransport limits the size of response bodies. Panics if the limit is not positive.
+func NewBodyLimitTransport(next http.RoundTripper, limit int64) http.RoundTripper {
+	if limit < 1 {
+		panic("maximum response body size must be greater than 0")
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &bodyLimitTransport{next: next, limit: limit}
+}
+
+func (t *bodyLimitTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	response, err := t.next.RoundTrip(r)
+	if err != nil || response.Body == nil || response.Body == http.NoBody ||
+		response.StatusCode == http.StatusSwitchingProtocols {
+		return response, err
+	}
+	if response.ContentLength > t.limit {
+		_ = response.Body.Close() // too large to drain
+		return nil, &ResponseBodyTooLargeError{Limit: t.limit}
+	}
+	response.Body = &limitedResponseBody{
+		ReadCloser: response.Body,
+		limit:      t.limit,
+		remaining:  t.limit,
+	}
+	return response, nil
+}
+
+type limitedResponseBody struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+}
+
+func (b *limitedResponseBody) Read(p []byte) (n int, err error) {
+	if b.remaining <= 0 {
+		// read past the limit to tell a body that ends at the limit from a longer one
+		var probe [1]byte
+		if n, err = b.ReadCloser.Read(probe[:]); n > 0 {
+			return 0, &ResponseBodyTooLargeError{Limit: b.limit}
+		}
+		return 0, err
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err = b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+// WithUnclosedBodyTracking logs response bodies that were garbage collected without being closed, along with the call site of the request. Tracking has a cost, so enable it in development and tests only.
+func WithUnclosedBodyTracking(logger *slog.Logger) Option {
+	return func(o *options) error {
+		if o.UnclosedBodyLogger != nil {
+			return errors.New("unclosed body tracking is already enabled")
+		}
+		if logger == nil {
+			return errors.New("cannot use a <nil> structured logger")
+		}
+		o.UnclosedBodyLogger = logger
+		return nil
+	}
+}
+
+type bodyTrackingTransport struct {
+	next   http.RoundTripper
+	logger *slog.Logger
+}
+
+func (t *bodyTrackingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	response, err := t.next.RoundTrip(r)
+	if err != nil || response.Body == nil || response.Body == http.NoBody {
+		return response, err
+	}
+	body := &trackedBody{
+		ReadCloser: response.Body,
+		Method:     r.Method,
+		URL:        r.URL.Redacted(),
+		CallSite:   callSite(),
+		Logger:     t.logger,
+	}
+	runtime.SetFinalizer(body, (*trackedBody).report)
+	// the transport keeps the original response until its body is closed
+	tracked := *response
+	tracked.Body = body
+	return &tracked, nil
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	Method   string
+	URL      string
+	CallSite string
+	Logger   *slog.Logger
+}
+
+func (b *trackedBody) Close() error {
+	runtime.SetFinalizer(b, nil)
+	return b.ReadCloser.Close()
+}
+
+func (b *trackedBody) report() {
+	b.Logger.Warn(
+		"HTTP response body was not closed",
+		slog.String("method", b.Method),
+		slog.String("url", b.URL),
+		slog.String("call_site", b.CallSite),
+	)
+	_ = b.ReadCloser.Close() // return the connection
+}
+
+// packagePrefix identifies functions of this package in stack traces.
+const packagePrefix = "github.com/dkotik/oakhttp/client."
+
+// callSite finds the first caller outside of the standard HTTP client and this package.
+func callSite() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		switch {
+		case strings.HasPrefix(frame.Function, "net/http."),
+			strings.HasPrefix(frame.Function, packagePrefix) && !strings.HasSuffix(frame.File, "_test.go"):
+		default:
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMaxResponseBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := strings.Repeat("x", 10)
+		if r.URL.Query().Has("large") {
+			body += "x"
+		}
+		if r.URL.Query().Has("chunked") {
+			w.(http.Flusher).Flush() // omits Content-Length
+		}
+		_, _ = io.WriteString(w, body)
+	}))
+	defer server.Close()
+
+	client, err := New(WithMaxResponseBodySize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := func(query string) (string, error) {
+		response, err := client.Get(server.URL + "?" + query)
+		if err != nil {
+			return "", err
+		}
+		defer DrainAndClose(response.Body)
+		b, err := io.ReadAll(response.Body)
+		return string(b), err
+	}
+
+	if body, err := read(""); err != nil || len(body) != 10 {
+		t.Fatal("body at the limit was not read:", body, err)
+	}
+	if body, err := read("chunked"); err != nil || len(body) != 10 {
+		t.Fatal("chunked body at the limit was not read:", body, err)
+	}
+	var tooLarge *ResponseBodyTooLargeError
+	if _, err = read("large"); !errors.As(err, &tooLarge) || tooLarge.Limit != 10 {
+		t.Fatal("declared content length was not limited:", err)
+	}
+	if _, err = read("large&chunked"); !errors.Is(err, ErrResponseBodyTooLarge) {
+		t.Fatal("chunked body was not limited:", err)
+	}
+}
+
+type lockedBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestUnclosedBodyTracking(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.URL.Path)
+	}))
+	defer server.Close()
+
+	log := &lockedBuffer{}
+	client, err := New(WithUnclosedBodyTracking(slog.New(slog.NewTextHandler(log, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	func() {
+		response, err := client.Get(server.URL + "/closed")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = DrainAndClose(response.Body); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = client.Get(server.URL + "/leaked"); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for range 50 {
+		runtime.GC()
+		if strings.Contains(log.String(), "/leaked") {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	output := log.String()
+	if !strings.Contains(output, "/leaked") || !strings.Contains(output, "body_test.go:") {
+		t.Fatal("leaked body was not reported with its call site:", output)
+	}
+	if strings.Contains(output, "/closed") {
+		t.Fatal("closed body was reported:", output)
+	}
+}
@@ -0,0 +1,398 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCircuitBreakerConsecutiveFailures = 5
+	DefaultCircuitBreakerFailureRatio        = 0.5
+	DefaultCircuitBreakerMinimumRequests     = 20
+	DefaultCircuitBreakerWindow              = time.Second * 10
+	DefaultCircuitBreakerOpenTimeout         = time.Second * 5
+	DefaultCircuitBreakerHalfOpenRequests    = 1
+)
+
+// CircuitState is the state of a circuit breaker for a single host.
+type CircuitState uint8
+
+const (
+	// CircuitClosed lets requests through while counting failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests immediately with [CircuitOpenError].
+	CircuitOpen
+	// CircuitHalfOpen lets a few probe requests through to test whether the host recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", uint8(s))
+	}
+}
+
+// ErrCircuitOpen matches any [CircuitOpenError] using [errors.Is].
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned without making a request while the host is considered unavailable.
+type CircuitOpenError struct {
+	Host string
+	// RetryAfter is the time left until the next probe request is allowed.
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for host %q is open", e.Host)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitStateChange is reported to the [WithCircuitBreakerStateChange] hook.
+type CircuitStateChange struct {
+	Host string
+	From CircuitState
+	To   CircuitState
+}
+
+type circuitBreakerOptions struct {
+	ConsecutiveFailures int
+	FailureRatio        float64
+	MinimumRequests     int
+	Window              time.Duration
+	OpenTimeout         time.Duration
+	HalfOpenRequests    int
+	StateChange         func(CircuitStateChange)
+}
+
+type CircuitBreakerOption func(*circuitBreakerOptions) error
+
+// WithCircuitBreakerConsecutiveFailures opens the circuit after the number of failed requests in a row.
+func WithCircuitBreakerConsecutiveFailures(n int) CircuitBreakerOption {
+	return func(o *circuitBreakerOptions) error {
+		if o.ConsecutiveFailures != 0 {
+			return errors.New("consecutive failure threshold is already set")
+		}
+		if n < 1 {
+			return errors.New("consecutive failure threshold must be greater than 0")
+		}
+		o.ConsecutiveFailures = n
+		return nil
+	}
+}
+
+// WithCircuitBreakerFailureRatio opens the circuit when the share of failed requests within the counting window reaches the ratio. The ratio is not checked until the window has at least the minimum number of requests.
+func WithCircuitBreakerFailureRatio(ratio float64, minimumRequests int) CircuitBreakerOption {
+	return func(o *circuitBreakerOptions) error {
+		if o.FailureRatio != 0 {
+			return errors.New("failure ratio threshold is already set")
+		}
+		if ratio <= 0 || ratio > 1 {
+			return errors.New("failure ratio must be greater than 0 and not exceed 1")
+		}
+		if minimumRequests < 1 {
+			return errors.New("minimum number of requests must be greater than 0")
+		}
+		o.FailureRatio = ratio
+		o.MinimumRequests = minimumRequests
+		return nil
+	}
+}
+
+// WithCircuitBreakerWindow sets how often the failure ratio counts are reset while the circuit is closed.
+func WithCircuitBreakerWindow(d time.Duration) CircuitBreakerOption {
+	return func(o *circuitBreakerOptions) error {
+		if o.Window != 0 {
+			return errors.New("circuit breaker window is already set")
+		}
+		if d < time.Second {
+			return errors.New("circuit breaker window must be at least one second")
+		}
+		if d > time.Hour {
+			return errors.New("circuit breaker window must not exceed one hour")
+		}
+		o.Window = d
+		return nil
+	}
+}
+
+// WithCircuitBreakerOpenTimeout sets how long the circuit stays open before probe requests are allowed.
+func WithCircuitBreakerOpenTimeout(d time.Duration) CircuitBreakerOption {
+	return func(o *circuitBreakerOptions) error {
+		if o.OpenTimeout != 0 {
+			return errors.New("circuit breaker open timeout is already set")
+		}
+		if d < time.Millisecond*10 {
+			return errors.New("circuit breaker open timeout must be at least 10ms")
+		}
+		if d > time.Hour {
+			return errors.New("circuit breaker open timeout must not exceed one hour")
+		}
+		o.OpenTimeout = d
+		return nil
+	}
+}
+
+// WithCircuitBreakerHalfOpenRequests sets how many probe requests may run at once while the circuit is half-open. The circuit closes after as many probes succeed, and opens again after any one of them fails.
+func WithCircuitBreakerHalfOpenRequests(n int) CircuitBreakerOption {
+	return func(o *circuitBreakerOptions) error {
+		if o.HalfOpenRequests != 0 {
+			return errors.New("half-open request limit is already set")
+		}
+		if n < 1 {
+			return errors.New("half-open request limit must be greater than 0")
+		}
+		if n > 100 {
+			return errors.New("half-open request limit greater than 100 is unreasonable")
+		}
+		o.HalfOpenRequests = n
+		return nil
+	}
+}
+
+// WithCircuitBreakerStateChange calls the function whenever a host circuit changes state. Use it to degrade features that depend on the host, such as skipping a captcha check while its provider is down. The function must not block.
+func WithCircuitBreakerStateChange(f func(CircuitStateChange)) CircuitBreakerOption {
+	return func(o *circuitBreakerOptions) error {
+		if o.StateChange != nil {
+			return errors.New("state change hook is already set")
+		}
+		if f == nil {
+			return errors.New("cannot use a <nil> state change hook")
+		}
+		o.StateChange = f
+		return nil
+	}
+}
+
+func newCircuitBreakerOptions(withOptions ...CircuitBreakerOption) (*circuitBreakerOptions, error) {
+	o := &circuitBreakerOptions{}
+	for _, option := range append(
+		withOptions,
+		func(o *circuitBreakerOptions) error { // defaults
+			if o.ConsecutiveFailures == 0 {
+				o.ConsecutiveFailures = DefaultCircuitBreakerConsecutiveFailures
+			}
+			if o.FailureRatio == 0 {
+				o.FailureRatio = DefaultCircuitBreakerFailureRatio
+				o.MinimumRequests = DefaultCircuitBreakerMinimumRequests
+			}
+			if o.Window == 0 {
+				o.Window = DefaultCircuitBreakerWindow
+			}
+			if o.OpenTimeout == 0 {
+				o.OpenTimeout = DefaultCircuitBreakerOpenTimeout
+			}
+			if o.HalfOpenRequests == 0 {
+				o.HalfOpenRequests = DefaultCircuitBreakerHalfOpenRequests
+			}
+			return nil
+		},
+	) {
+		if err := option(o); err != nil {
+			return nil, fmt.Errorf("cannot configure circuit breaker: %w", err)
+		}
+	}
+	return o, nil
+}
+
+// WithCircuitBreaker fails requests fast with [CircuitOpenError] to hosts that keep failing using [NewCircuitBreakerTransport]. Retries enabled with [WithRetries] stop at an open circuit.
+func WithCircuitBreaker(withOptions ...CircuitBreakerOption) Option {
+	return func(o *options) (err error) {
+		if o.CircuitBreaker != nil {
+			return errors.New("circuit breaker is already enabled")
+		}
+		o.CircuitBreaker, err = newCircuitBreakerOptions(withOptions...)
+		return err
+	}
+}
+
+// NewCircuitBreakerTransport tracks failures of each host separately. Transport errors and 5xx responses count as failures. Requests canceled by the caller do not count. Hosts that were not requested for longer than the window are forgotten.
+func NewCircuitBreakerTransport(next http.RoundTripper, withOptions ...CircuitBreakerOption) (http.RoundTripper, error) {
+	o, err := newCircuitBreakerOptions(withOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return newCircuitBreakerTransport(next, o), nil
+}
+
+func newCircuitBreakerTransport(next http.RoundTripper, o *circuitBreakerOptions) *circuitBreakerTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &circuitBreakerTransport{
+		next:                  next,
+		circuitBreakerOptions: o,
+		circuits:              make(map[string]*circuit),
+	}
+}
+
+type circuit struct {
+	State CircuitState
+	// Generation tells apart requests allowed before the last state change.
+	Generation  uint64
+	Since       time.Time
+	Requests    int
+	Failures    int
+	Consecutive int
+	Probes      int
+	Successes   int
+	// InFlight counts allowed requests whose outcome is not recorded yet. Circuits with requests in flight are never evicted.
+	InFlight int
+}
+
+type circuitBreakerTransport struct {
+	next http.RoundTripper
+	*circuitBreakerOptions
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	swept    time.Time
+}
+
+type circuitOutcome uint8
+
+const (
+	circuitSuccess circuitOutcome = iota
+	circuitFailure
+	circuitIgnored
+)
+
+func (t *circuitBreakerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	host := r.URL.Host
+	generation, err := t.allow(host)
+	if err != nil {
+		return nil, err
+	}
+	response, err := t.next.RoundTrip(r)
+	outcome := circuitSuccess
+	switch {
+	case err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, ErrDestinationBlocked)):
+		outcome = circuitIgnored
+	case err != nil, response.StatusCode >= http.StatusInternalServerError:
+		outcome = circuitFailure
+	}
+	t.record(host, generation, outcome)
+	return response, err
+}
+
+func (t *circuitBreakerTransport) transition(host string, c *circuit, to CircuitState, changes *[]CircuitStateChange) {
+	*changes = append(*changes, CircuitStateChange{Host: host, From: c.State, To: to})
+	*c = circuit{State: to, Generation: c.Generation + 1, Since: time.Now(), InFlight: c.InFlight}
+}
+
+// sweep evicts circuits of hosts that were not requested for a while, so that requests to many distinct hosts, such as user-supplied URLs, do not grow memory without bound. Evicting a closed circuit after its window loses nothing, because its counts would be reset anyway. Open circuits are kept for a window past their open timeout.
+func (t *circuitBreakerTransport) sweep(now time.Time) {
+	if now.Sub(t.swept) < t.Window {
+		return
+	}
+	t.swept = now
+	for host, c := range t.circuits {
+		if c.InFlight > 0 {
+			continue
+		}
+		idle := now.Sub(c.Since)
+		if c.State == CircuitOpen {
+			idle -= t.OpenTimeout
+		}
+		if idle >= t.Window {
+			delete(t.circuits, host)
+		}
+	}
+}
+
+func (t *circuitBreakerTransport) notify(changes []CircuitStateChange) {
+	if t.StateChange == nil {
+		return
+	}
+	for _, change := range changes {
+		t.StateChange(change)
+	}
+}
+
+func (t *circuitBreakerTransport) allow(host string) (generation uint64, err error) {
+	var changes []CircuitStateChange
+	defer func() { t.notify(changes) }()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.sweep(now)
+	c, ok := t.circuits[host]
+	if !ok {
+		c = &circuit{Since: now}
+		t.circuits[host] = c
+	}
+	switch c.State {
+	case CircuitOpen:
+		elapsed := now.Sub(c.Since)
+		if elapsed < t.OpenTimeout {
+			return 0, &CircuitOpenError{Host: host, RetryAfter: t.OpenTimeout - elapsed}
+		}
+		t.transition(host, c, CircuitHalfOpen, &changes)
+		fallthrough
+	case CircuitHalfOpen:
+		if c.Probes >= t.HalfOpenRequests {
+			return 0, &CircuitOpenError{Host: host}
+		}
+		c.Probes++
+	default:
+		if now.Sub(c.Since) >= t.Window {
+			*c = circuit{Generation: c.Generation, Since: now, InFlight: c.InFlight}
+		}
+	}
+	c.InFlight++
+	return c.Generation, nil
+}
+
+func (t *circuitBreakerTransport) record(host string, generation uint64, outcome circuitOutcome) {
+	var changes []CircuitStateChange
+	defer func() { t.notify(changes) }()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c := t.circuits[host]
+	c.InFlight--
+	if c.Generation != generation {
+		return // outcome of a request from a previous state
+	}
+	switch c.State {
+	case CircuitHalfOpen:
+		c.Probes--
+		switch outcome {
+		case circuitFailure:
+			t.transition(host, c, CircuitOpen, &changes)
+		case circuitSuccess:
+			if c.Successes++; c.Successes >= t.HalfOpenRequests {
+				t.transition(host, c, CircuitClosed, &changes)
+			}
+		}
+	default:
+		if outcome == circuitIgnored {
+			return
+		}
+		c.Requests++
+		if outcome == circuitSuccess {
+			c.Consecutive = 0
+			return
+		}
+		c.Failures++
+		c.Consecutive++
+		if c.Consecutive >= t.ConsecutiveFailures ||
+			(c.Requests >= t.MinimumRequests && float64(c.Failures)/float64(c.Requests) >= t.FailureRatio) {
+			t.transition(host, c, CircuitOpen, &changes)
+		}
+	}
+}
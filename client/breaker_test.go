@@ -0,0 +1,195 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	var (
+		mu      sync.Mutex
+		changes []CircuitStateChange
+	)
+	client, err := New(WithCircuitBreaker(
+		WithCircuitBreakerConsecutiveFailures(3),
+		WithCircuitBreakerOpenTimeout(time.Millisecond*50),
+		WithCircuitBreakerStateChange(func(c CircuitStateChange) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, c)
+		}),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func() error {
+		response, err := client.Get(server.URL)
+		if err == nil {
+			_ = response.Body.Close()
+		}
+		return err
+	}
+
+	for range 3 {
+		if err = get(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = get()
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("circuit did not open:", err)
+	}
+	if openErr.RetryAfter <= 0 || openErr.RetryAfter > time.Millisecond*50 {
+		t.Fatal("unexpected retry delay:", openErr.RetryAfter)
+	}
+
+	time.Sleep(time.Millisecond * 60)
+	if err = get(); err != nil { // probe fails
+		t.Fatal(err)
+	}
+	if err = get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("failed probe did not open the circuit:", err)
+	}
+
+	time.Sleep(time.Millisecond * 60)
+	failing.Store(false)
+	if err = get(); err != nil {
+		t.Fatal(err)
+	}
+	if err = get(); err != nil {
+		t.Fatal("circuit did not close after a successful probe:", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(changes) != len(expected) {
+		t.Fatalf("unexpected state changes: %+v", changes)
+	}
+	for i, state := range expected {
+		if changes[i].To != state || changes[i].Host != server.Listener.Addr().String() {
+			t.Fatalf("state change %d is %+v, expected %s", i, changes[i], state)
+		}
+	}
+}
+
+func TestCircuitBreakerFailureRatio(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1)%2 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	transport, err := NewCircuitBreakerTransport(nil, WithCircuitBreakerFailureRatio(0.5, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: transport}
+	for i := range 7 {
+		response, err := client.Get(server.URL)
+		if i < 6 {
+			if err != nil {
+				t.Fatal("circuit opened before reaching the minimum number of requests:", err)
+			}
+			_ = response.Body.Close()
+			continue
+		}
+		if !errors.Is(err, ErrCircuitOpen) {
+			t.Fatal("circuit did not open at the failure ratio:", err)
+		}
+	}
+}
+
+func TestCircuitBreakerStopsRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := New(
+		WithRetries(WithRetryAttempts(5), WithRetryBackoff(time.Millisecond, time.Millisecond)),
+		WithCircuitBreaker(WithCircuitBreakerConsecutiveFailures(2)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Get(server.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("retries did not stop at the open circuit:", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatal("unexpected number of requests:", calls.Load())
+	}
+}
+
+func TestCircuitBreakerEvictsIdleHosts(t *testing.T) {
+	o, err := newCircuitBreakerOptions(WithCircuitBreakerOpenTimeout(time.Millisecond * 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.Window = time.Millisecond * 20 // below the option minimum to keep the test fast
+	transport := newCircuitBreakerTransport(nil, o)
+
+	for i := range 100 {
+		host := fmt.Sprintf("host%d.example.com", i)
+		generation, err := transport.allow(host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		transport.record(host, generation, circuitFailure)
+	}
+	inFlight, err := transport.allow("pending.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range o.ConsecutiveFailures {
+		generation, err := transport.allow("down.example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		transport.record("down.example.com", generation, circuitFailure)
+	}
+
+	time.Sleep(o.Window + time.Millisecond*5)
+	if _, err = transport.allow("fresh.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	transport.mu.Lock()
+	_, pending := transport.circuits["pending.example.com"]
+	_, down := transport.circuits["down.example.com"]
+	size := len(transport.circuits)
+	transport.mu.Unlock()
+	if !pending || !down || size != 3 {
+		t.Fatalf("idle circuits were not evicted: %d left, pending kept %t, open kept %t", size, pending, down)
+	}
+	transport.record("pending.example.com", inFlight, circuitSuccess)
+
+	time.Sleep(o.Window + o.OpenTimeout + time.Millisecond*5)
+	if _, err = transport.allow("fresh.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.circuits) != 1 {
+		t.Fatal("unexpected number of circuits:", len(transport.circuits))
+	}
+}
@@ -1,14 +1,21 @@
 /*
 Package client provides a more secure standard HTTP client.
 
-Higher security is achieved by setting short reasonable timeouts. Default HTTP client [leaks go routines][read-response] if the `response.Body` is not fully read and closed on **every** request or if the server hangs the connection or responds slowly.
+Higher security is achieved by setting short reasonable timeouts. Default HTTP client [leaks go routines][read-response] if the `response.Body` is not fully read and closed on **every** request or if the server hangs the connection or responds slowly. Close bodies with [DrainAndClose] so that connections are reused, and find the bodies that are never closed with [WithUnclosedBodyTracking].
 
 ## Default HTTP Client
 
 > Making things worse is the fact that a bare http.Client will use a default http.Transport called http.DefaultTransport, which is another global value that behaves the same way. So it is not simply enough to replace http.DefaultClient with &http.Client{}. (https://pkg.go.dev/github.com/hashicorp/go-cleanhttp#section-readme)
 
+## User-Supplied URLs
+
+Use [WithSSRFProtection] for clients that fetch URLs provided by users, such as webhooks and link previews. Otherwise, such requests can reach loopback, private network, and cloud metadata addresses.
+
+## JSON APIs
+
+[NewEndpoint] wraps a single API method with typed requests, responses, and error bodies. It negotiates content types, limits response size, and applies a deadline to each call.
+
 TODO: Use Hashicorp's `go-cleanhttp` package to get true copies of http.Client{}.
-TODO: Include retriable client from here: https://github.com/projectdiscovery/retryablehttp-go
 
 [read-response]: https://manishrjain.com/must-close-golang-http-response
 */
@@ -38,17 +45,45 @@ func New(withOptions ...Option) (*http.Client, error) {
 		return nil, errors.New("sum of header timeouts must not exceed the total Timeout")
 	}
 
+	dialer := &net.Dialer{
+		Timeout:   o.Timeout,
+		KeepAlive: o.KeepAlive,
+	}
+	var checkRedirect func(*http.Request, []*http.Request) error
+	if o.SSRF != nil {
+		dialer.Control = o.SSRF.Control
+		checkRedirect = o.SSRF.CheckRedirect
+	}
+	var transport http.RoundTripper = &http.Transport{
+		MaxConnsPerHost:       o.MaxConnsPerHost,
+		MaxIdleConnsPerHost:   o.MaxIdleConnsPerHost,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   o.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: o.ResponseHeaderTimeout,
+		ExpectContinueTimeout: o.ExpectContinueTimeout,
+	}
+	if o.SSRF != nil {
+		transport = &ssrfTransport{next: transport, ssrfOptions: o.SSRF}
+	}
+	if o.TraceContext {
+		transport = NewTraceContextTransport(transport)
+	}
+	if o.CircuitBreaker != nil {
+		transport = newCircuitBreakerTransport(transport, o.CircuitBreaker)
+	}
+	if o.Retry != nil {
+		transport = newRetryTransport(transport, o.Retry)
+	}
+	if o.MaxResponseBodySize != 0 {
+		transport = NewBodyLimitTransport(transport, o.MaxResponseBodySize)
+	}
+	if o.UnclosedBodyLogger != nil {
+		transport = &bodyTrackingTransport{next: transport, logger: o.UnclosedBodyLogger}
+	}
+
 	return &http.Client{
-		Timeout: o.Timeout,
-		Transport: &http.Transport{
-			MaxConnsPerHost:     o.MaxConnsPerHost,
-			MaxIdleConnsPerHost: o.MaxIdleConnsPerHost,
-			DialContext: (&net.Dialer{
-				Timeout:   o.Timeout,
-				KeepAlive: o.KeepAlive,
-			}).DialContext,
-			TLSHandshakeTimeout:   o.TLSHandshakeTimeout,
-			ResponseHeaderTimeout: o.ResponseHeaderTimeout,
-			ExpectContinueTimeout: o.ExpectContinueTimeout,
-		}}, nil
+		Timeout:       o.Timeout,
+		Transport:     transport,
+		CheckRedirect: checkRedirect,
+	}, nil
 }
@@ -1,6 +1,13 @@
 package client
 
-import "testing"
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dkotik/oakhttp"
+)
 
 func TestClientCreation(t *testing.T) {
 	_, err := New()
@@ -8,3 +15,35 @@ func TestClientCreation(t *testing.T) {
 		t.Fatal("cannot create HTTP client with default settings:", err)
 	}
 }
+
+func TestTraceContextPropagation(t *testing.T) {
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get(oakhttp.TraceParentHeader)
+	}))
+	defer server.Close()
+
+	client, err := New(WithTraceContextPropagation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := oakhttp.NewTraceContext("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "")
+	request, err := http.NewRequestWithContext(
+		oakhttp.ContextWithTracing(context.Background(), tc),
+		http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	if traceParent != "00-4bf92f3577b34da6a3ce929d0e0e4736-"+tc.SpanID+"-01" {
+		t.Fatal("trace context was not propagated:", traceParent)
+	}
+	if request.Header.Get(oakhttp.TraceParentHeader) != "" {
+		t.Fatal("original request was modified")
+	}
+}
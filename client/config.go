@@ -0,0 +1,42 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/dkotik/oakhttp/config"
+)
+
+var configurationFields = []config.Field[options]{
+	config.Duration("timeout", WithTimeout),
+	config.Duration("keep_alive_timeout", WithKeepAliveTimeout),
+	config.Duration("tls_handshake_timeout", WithTLSHandshakeTimeout),
+	config.Duration("response_header_timeout", WithResponseHeaderTimeout),
+	config.Duration("expect_continue_timeout", WithExpectContinueTimeout),
+	config.Int("connection_limit", WithConnectionLimit),
+	config.Int("idle_connection_limit_per_host", WithIdleConnectionLimitPerHost),
+	config.Bool("trace_context", WithTraceContextPropagation),
+}
+
+// WithEnvironment loads settings from environment variables with the given prefix: TIMEOUT, KEEP_ALIVE_TIMEOUT, TLS_HANDSHAKE_TIMEOUT, RESPONSE_HEADER_TIMEOUT, EXPECT_CONTINUE_TIMEOUT, CONNECTION_LIMIT, IDLE_CONNECTION_LIMIT_PER_HOST, and TRACE_CONTEXT.
+func WithEnvironment(prefix string) Option {
+	return func(o *options) error {
+		if err := config.Apply(o, config.Environment(prefix), configurationFields...); err != nil {
+			return fmt.Errorf("cannot configure client from environment: %w", err)
+		}
+		return nil
+	}
+}
+
+// WithConfigFile loads the same settings as [WithEnvironment] from a JSON configuration file with lower case field names, like "tls_handshake_timeout". Unknown fields are rejected.
+func WithConfigFile(path string) Option {
+	return func(o *options) error {
+		source, err := config.File(path)
+		if err == nil {
+			err = config.Apply(o, source, configurationFields...)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot configure client from file: %w", err)
+		}
+		return nil
+	}
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"errors"
+	"log/slog"
 	"time"
 )
 
@@ -13,6 +14,12 @@ type options struct {
 	TLSHandshakeTimeout   time.Duration
 	ResponseHeaderTimeout time.Duration
 	ExpectContinueTimeout time.Duration
+	TraceContext          bool
+	Retry                 *retryOptions
+	CircuitBreaker        *circuitBreakerOptions
+	SSRF                  *ssrfOptions
+	MaxResponseBodySize   int64
+	UnclosedBodyLogger    *slog.Logger
 }
 
 type Option func(*options) error
@@ -135,3 +142,14 @@ func WithIdleConnectionLimitPerHost(n int) Option {
 		return nil
 	}
 }
+
+// WithTraceContextPropagation continues W3C traces in outgoing requests using [NewTraceContextTransport].
+func WithTraceContextPropagation() Option {
+	return func(o *options) error {
+		if o.TraceContext {
+			return errors.New("trace context propagation is already enabled")
+		}
+		o.TraceContext = true
+		return nil
+	}
+}
@@ -0,0 +1,357 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultRetryAttempts       = 2
+	DefaultRetryInitialBackoff = time.Millisecond * 100
+	DefaultRetryMaximumBackoff = time.Second * 2
+	DefaultRetryBudgetRatio    = 0.2
+	DefaultRetryBudgetBurst    = 10
+)
+
+// RetryEvent describes a failed attempt that is about to be retried.
+type RetryEvent struct {
+	Request *http.Request
+	// Attempt counts the failed attempts, starting with 1.
+	Attempt int
+	// StatusCode is 0 when the attempt failed with an error.
+	StatusCode int
+	Err        error
+	Delay      time.Duration
+}
+
+type retryOptions struct {
+	Attempts       int
+	InitialBackoff time.Duration
+	MaximumBackoff time.Duration
+	BudgetRatio    float64
+	BudgetBurst    int
+	Hook           func(RetryEvent)
+	Logger         *slog.Logger
+}
+
+type RetryOption func(*retryOptions) error
+
+// WithRetryAttempts limits how many times a single request is retried after the first attempt fails.
+func WithRetryAttempts(n int) RetryOption {
+	return func(o *retryOptions) error {
+		if o.Attempts != 0 {
+			return errors.New("retry attempts are already set")
+		}
+		if n < 1 {
+			return errors.New("retry attempts must be greater than 0")
+		}
+		if n > 10 {
+			return errors.New("more than 10 retry attempts is unreasonable")
+		}
+		o.Attempts = n
+		return nil
+	}
+}
+
+// WithRetryBackoff sets the range of delays between attempts. The delay doubles with each attempt up to the maximum and is randomized to spread out retries from many clients. A Retry-After response header longer than the maximum stops retries.
+func WithRetryBackoff(initial, maximum time.Duration) RetryOption {
+	return func(o *retryOptions) error {
+		if o.InitialBackoff != 0 {
+			return errors.New("retry backoff is already set")
+		}
+		if initial < time.Millisecond {
+			return errors.New("initial retry backoff must be at least one millisecond")
+		}
+		if maximum < initial {
+			return errors.New("maximum retry backoff must not be less than the initial backoff")
+		}
+		if maximum > time.Minute {
+			return errors.New("maximum retry backoff must not exceed one minute")
+		}
+		o.InitialBackoff = initial
+		o.MaximumBackoff = maximum
+		return nil
+	}
+}
+
+// WithRetryBudget limits retries across all requests made by the client, so that retries do not multiply the load on a struggling service. Each request earns a ratio of a retry, and up to the burst of unused retries is saved.
+func WithRetryBudget(ratio float64, burst int) RetryOption {
+	return func(o *retryOptions) error {
+		if o.BudgetRatio != 0 {
+			return errors.New("retry budget is already set")
+		}
+		if ratio <= 0 || ratio > 1 {
+			return errors.New("retry budget ratio must be greater than 0 and not exceed 1")
+		}
+		if burst < 1 {
+			return errors.New("retry budget burst must be greater than 0")
+		}
+		o.BudgetRatio = ratio
+		o.BudgetBurst = burst
+		return nil
+	}
+}
+
+// WithRetryHook calls the function before each retry.
+func WithRetryHook(f func(RetryEvent)) RetryOption {
+	return func(o *retryOptions) error {
+		if o.Hook != nil {
+			return errors.New("retry hook is already set")
+		}
+		if f == nil {
+			return errors.New("cannot use a <nil> retry hook")
+		}
+		o.Hook = f
+		return nil
+	}
+}
+
+// WithRetryLogger logs each retry at warning level.
+func WithRetryLogger(logger *slog.Logger) RetryOption {
+	return func(o *retryOptions) error {
+		if o.Logger != nil {
+			return errors.New("retry logger is already set")
+		}
+		if logger == nil {
+			return errors.New("cannot use a <nil> structured logger")
+		}
+		o.Logger = logger
+		return nil
+	}
+}
+
+func newRetryOptions(withOptions ...RetryOption) (*retryOptions, error) {
+	o := &retryOptions{}
+	for _, option := range append(
+		withOptions,
+		func(o *retryOptions) error { // defaults
+			if o.Attempts == 0 {
+				o.Attempts = DefaultRetryAttempts
+			}
+			if o.InitialBackoff == 0 {
+				o.InitialBackoff = DefaultRetryInitialBackoff
+				o.MaximumBackoff = DefaultRetryMaximumBackoff
+			}
+			if o.BudgetRatio == 0 {
+				o.BudgetRatio = DefaultRetryBudgetRatio
+				o.BudgetBurst = DefaultRetryBudgetBurst
+			}
+			return nil
+		},
+	) {
+		if err := option(o); err != nil {
+			return nil, fmt.Errorf("cannot configure retries: %w", err)
+		}
+	}
+	return o, nil
+}
+
+// WithRetries retries idempotent requests that failed to connect or received 429 Too Many Requests or 503 Service Unavailable responses using [NewRetryTransport]. All attempts must complete within [WithTimeout].
+func WithRetries(withOptions ...RetryOption) Option {
+	return func(o *options) (err error) {
+		if o.Retry != nil {
+			return errors.New("retries are already enabled")
+		}
+		o.Retry, err = newRetryOptions(withOptions...)
+		return err
+	}
+}
+
+type retryTransport struct {
+	next http.RoundTripper
+	*retryOptions
+
+	mu     sync.Mutex
+	budget float64
+}
+
+// NewRetryTransport retries idempotent requests that failed to connect or received 429 Too Many Requests or 503 Service Unavailable responses. Requests with bodies are retried only if they provide [http.Request.GetBody], which [http.NewRequest] sets for common body types. Requests with an Idempotency-Key header are treated as idempotent.
+func NewRetryTransport(next http.RoundTripper, withOptions ...RetryOption) (http.RoundTripper, error) {
+	o, err := newRetryOptions(withOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return newRetryTransport(next, o), nil
+}
+
+func newRetryTransport(next http.RoundTripper, o *retryOptions) *retryTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &retryTransport{
+		next:         next,
+		retryOptions: o,
+		budget:       float64(o.BudgetBurst),
+	}
+}
+
+func (t *retryTransport) deposit() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.budget = min(t.budget+t.BudgetRatio, float64(t.BudgetBurst))
+}
+
+func (t *retryTransport) withdraw() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.budget < 1 {
+		return false
+	}
+	t.budget--
+	return true
+}
+
+func isRetryable(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		if r.Header.Get("Idempotency-Key") == "" && r.Header.Get("X-Idempotency-Key") == "" {
+			return false
+		}
+	}
+	return r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
+}
+
+// isConnectionError returns true if the request may not have reached the server or the connection broke before the response.
+func isConnectionError(err error) bool {
+	var (
+		certificateErr *tls.CertificateVerificationError
+		authorityErr   x509.UnknownAuthorityError
+		hostnameErr    x509.HostnameError
+	)
+	switch {
+	case errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, ErrDestinationBlocked),
+		errors.As(err, &certificateErr),
+		errors.As(err, &authorityErr),
+		errors.As(err, &hostnameErr):
+		return false
+	case errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET):
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// retryAfter reads the Retry-After header as either seconds or an HTTP date.
+func retryAfter(response *http.Response) (time.Duration, bool) {
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	ceiling := t.MaximumBackoff
+	if attempt < 32 {
+		ceiling = min(t.InitialBackoff<<(attempt-1), t.MaximumBackoff)
+	}
+	// full jitter: https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+func (t *retryTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if !isRetryable(r) {
+		return t.next.RoundTrip(r)
+	}
+	t.deposit()
+	ctx := r.Context()
+	attempt := r
+	for i := 1; ; i++ {
+		response, err := t.next.RoundTrip(attempt)
+		if i > t.Attempts {
+			return response, err
+		}
+
+		var delay time.Duration
+		switch {
+		case err != nil:
+			if !isConnectionError(err) {
+				return nil, err
+			}
+			delay = t.backoff(i)
+		case response.StatusCode == http.StatusTooManyRequests,
+			response.StatusCode == http.StatusServiceUnavailable:
+			var ok bool
+			if delay, ok = retryAfter(response); !ok {
+				delay = t.backoff(i)
+			} else if delay > t.MaximumBackoff {
+				return response, nil // server asked to come back much later
+			}
+		default:
+			return response, nil
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return response, err
+		}
+		if !t.withdraw() {
+			return response, err
+		}
+
+		next := r.Clone(ctx)
+		if r.GetBody != nil && r.Body != nil && r.Body != http.NoBody {
+			if next.Body, err = r.GetBody(); err != nil {
+				return response, fmt.Errorf("cannot replay request body: %w", err)
+			}
+		}
+		event := RetryEvent{Request: r, Attempt: i, Err: err, Delay: delay}
+		if response != nil {
+			event.StatusCode = response.StatusCode
+			_ = DrainAndClose(response.Body)
+		}
+		t.notify(ctx, event)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, context.Cause(ctx)
+		case <-timer.C:
+		}
+		attempt = next
+	}
+}
+
+func (t *retryTransport) notify(ctx context.Context, event RetryEvent) {
+	if t.Hook != nil {
+		t.Hook(event)
+	}
+	if t.Logger != nil {
+		attrs := []slog.Attr{
+			slog.String("method", event.Request.Method),
+			slog.String("url", event.Request.URL.Redacted()),
+			slog.Int("attempt", event.Attempt),
+			slog.Duration("delay", event.Delay),
+		}
+		if event.StatusCode != 0 {
+			attrs = append(attrs, slog.Int("status_code", event.StatusCode))
+		}
+		if event.Err != nil {
+			attrs = append(attrs, slog.Any("error", event.Err))
+		}
+		t.Logger.LogAttrs(ctx, slog.LevelWarn, "retrying HTTP request", attrs...)
+	}
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch n := calls.Add(1); {
+		case n == 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case n == 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case n > 2:
+			_, _ = w.Write(body)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var events []RetryEvent
+	client, err := New(WithRetries(
+		WithRetryAttempts(3),
+		WithRetryBackoff(time.Millisecond, time.Millisecond*10),
+		WithRetryHook(func(e RetryEvent) { events = append(events, e) }),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("replayed body", func(t *testing.T) {
+		request, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("payload"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		if response.StatusCode != http.StatusOK || string(body) != "payload" {
+			t.Fatal("request body was not replayed:", response.StatusCode, string(body))
+		}
+		if len(events) != 2 || events[0].StatusCode != http.StatusServiceUnavailable || events[0].Delay != 0 || events[1].Attempt != 2 {
+			t.Fatalf("unexpected retry events: %+v", events)
+		}
+	})
+
+	t.Run("not idempotent", func(t *testing.T) {
+		calls.Store(0)
+		response, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+			t.Fatal("POST request was retried")
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		calls.Store(-10)
+		events = nil
+		response, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusServiceUnavailable || calls.Load() != -6 || len(events) != 3 {
+			t.Fatal("retry attempts were not limited:", calls.Load())
+		}
+	})
+}
+
+func TestRetryConnectionErrors(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := ln.Addr().String()
+	var accepted atomic.Int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = http.ReadRequest(bufio.NewReader(conn))
+			if accepted.Add(1) == 1 {
+				_ = conn.Close() // broken before the response
+				continue
+			}
+			_, _ = io.WriteString(conn, "HTTP/1.1 204 No Content\r\nConnection: close\r\n\r\n")
+			_ = conn.Close()
+		}
+	}()
+	defer ln.Close()
+
+	transport, err := NewRetryTransport(nil, WithRetryBackoff(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := (&http.Client{Transport: transport}).Get("http://" + address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusNoContent || accepted.Load() != 2 {
+		t.Fatal("broken connection was not retried:", response.StatusCode, accepted.Load())
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	transport, err := NewRetryTransport(nil,
+		WithRetryAttempts(5),
+		WithRetryBackoff(time.Millisecond, time.Millisecond),
+		WithRetryBudget(0.1, 2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: transport}
+	for range 2 {
+		response, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = response.Body.Close()
+	}
+	if calls.Load() != 4 { // two requests and two retries from the burst
+		t.Fatal("retry budget was not enforced:", calls.Load())
+	}
+}
+
+func TestRetryAfterBeyondMaximumBackoff(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, err := New(WithRetries())
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 {
+		t.Fatal("distant Retry-After was not respected:", calls.Load())
+	}
+}
@@ -0,0 +1,251 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// DefaultDeniedNetworks cover loopback, private, link-local, carrier-grade NAT, multicast, and reserved ranges, as well as IPv6 ranges that embed IPv4 addresses.
+var DefaultDeniedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"), // cloud metadata services
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/32"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// ErrDestinationBlocked matches any [DestinationBlockedError] using [errors.Is].
+var ErrDestinationBlocked = errors.New("destination is blocked")
+
+// DestinationBlockedError is returned for requests that [WithSSRFProtection] refused to make.
+type DestinationBlockedError struct {
+	// Destination is the URL or the resolved network address.
+	Destination string
+	Reason      string
+}
+
+func (e *DestinationBlockedError) Error() string {
+	return fmt.Sprintf("destination %q is blocked: %s", e.Destination, e.Reason)
+}
+
+func (e *DestinationBlockedError) Is(target error) bool {
+	return target == ErrDestinationBlocked
+}
+
+type ssrfOptions struct {
+	DeniedNetworks  []netip.Prefix
+	AllowedNetworks []netip.Prefix
+	AllowedSchemes  []string
+	AllowedPorts    []uint16
+}
+
+type SSRFOption func(*ssrfOptions) error
+
+func parseNetworks(cidrs []string) ([]netip.Prefix, error) {
+	if len(cidrs) == 0 {
+		return nil, errors.New("provide at least one network")
+	}
+	networks := make([]netip.Prefix, len(cidrs))
+	for i, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", cidr, err)
+		}
+		networks[i] = prefix.Masked()
+	}
+	return networks, nil
+}
+
+// WithDeniedNetworks blocks connections to the CIDR ranges, like "10.0.0.0/8". Replaces [DefaultDeniedNetworks].
+func WithDeniedNetworks(cidrs ...string) SSRFOption {
+	return func(o *ssrfOptions) (err error) {
+		if o.DeniedNetworks != nil {
+			return errors.New("denied networks are already set")
+		}
+		o.DeniedNetworks, err = parseNetworks(cidrs)
+		return err
+	}
+}
+
+// WithAllowedNetworks permits connections to the CIDR ranges even if they fall within denied networks, such as a trusted internal service.
+func WithAllowedNetworks(cidrs ...string) SSRFOption {
+	return func(o *ssrfOptions) (err error) {
+		if o.AllowedNetworks != nil {
+			return errors.New("allowed networks are already set")
+		}
+		o.AllowedNetworks, err = parseNetworks(cidrs)
+		return err
+	}
+}
+
+// WithAllowedSchemes limits URL schemes. Defaults to "https" and "http".
+func WithAllowedSchemes(schemes ...string) SSRFOption {
+	return func(o *ssrfOptions) error {
+		if o.AllowedSchemes != nil {
+			return errors.New("allowed schemes are already set")
+		}
+		if len(schemes) == 0 {
+			return errors.New("provide at least one scheme")
+		}
+		for _, scheme := range schemes {
+			scheme = strings.ToLower(scheme)
+			if scheme != "http" && scheme != "https" {
+				return fmt.Errorf("unsupported scheme %q", scheme)
+			}
+			o.AllowedSchemes = append(o.AllowedSchemes, scheme)
+		}
+		return nil
+	}
+}
+
+// WithAllowedPorts limits destination ports. Defaults to 443 and 80.
+func WithAllowedPorts(ports ...uint16) SSRFOption {
+	return func(o *ssrfOptions) error {
+		if o.AllowedPorts != nil {
+			return errors.New("allowed ports are already set")
+		}
+		if len(ports) == 0 {
+			return errors.New("provide at least one port")
+		}
+		for _, port := range ports {
+			if port == 0 {
+				return errors.New("cannot allow port 0")
+			}
+		}
+		o.AllowedPorts = ports
+		return nil
+	}
+}
+
+// WithSSRFProtection guards against server-side request forgery when fetching user-supplied URLs, such as webhooks and link previews. Resolved IP addresses are checked when connecting, so that DNS records cannot point the client at internal services after validation. Redirects are checked the same way. Blocked requests fail with [DestinationBlockedError]. Requests through a proxy check the address of the proxy instead.
+func WithSSRFProtection(withOptions ...SSRFOption) Option {
+	return func(o *options) error {
+		if o.SSRF != nil {
+			return errors.New("SSRF protection is already enabled")
+		}
+		ssrf := &ssrfOptions{}
+		for _, option := range append(
+			withOptions,
+			func(o *ssrfOptions) error { // defaults
+				if o.DeniedNetworks == nil {
+					o.DeniedNetworks = DefaultDeniedNetworks
+				}
+				if o.AllowedSchemes == nil {
+					o.AllowedSchemes = []string{"https", "http"}
+				}
+				if o.AllowedPorts == nil {
+					o.AllowedPorts = []uint16{443, 80}
+				}
+				return nil
+			},
+		) {
+			if err := option(ssrf); err != nil {
+				return fmt.Errorf("cannot configure SSRF protection: %w", err)
+			}
+		}
+		o.SSRF = ssrf
+		return nil
+	}
+}
+
+// checkURL validates the scheme and the port of a request or a redirect.
+func (o *ssrfOptions) checkURL(u *url.URL) error {
+	scheme := strings.ToLower(u.Scheme)
+	if !slices.Contains(o.AllowedSchemes, scheme) {
+		return &DestinationBlockedError{Destination: u.Redacted(), Reason: "scheme is not allowed"}
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if scheme == "https" {
+			port = "443"
+		}
+	}
+	if !o.isAllowedPort(port) {
+		return &DestinationBlockedError{Destination: u.Redacted(), Reason: "port is not allowed"}
+	}
+	return nil
+}
+
+func (o *ssrfOptions) isAllowedPort(port string) bool {
+	n, err := strconv.ParseUint(port, 10, 16)
+	return err == nil && slices.Contains(o.AllowedPorts, uint16(n))
+}
+
+// checkAddress validates the resolved address right before connecting.
+func (o *ssrfOptions) checkAddress(network, address string) error {
+	if network != "tcp" && network != "tcp4" && network != "tcp6" {
+		return &DestinationBlockedError{Destination: address, Reason: "network is not allowed"}
+	}
+	addressPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return &DestinationBlockedError{Destination: address, Reason: "address is not an IP address"}
+	}
+	if !o.isAllowedPort(strconv.Itoa(int(addressPort.Port()))) {
+		return &DestinationBlockedError{Destination: address, Reason: "port is not allowed"}
+	}
+	ip := addressPort.Addr().WithZone("").Unmap() // zoned addresses never match prefixes
+	contains := func(p netip.Prefix) bool { return p.Contains(ip) }
+	if slices.ContainsFunc(o.AllowedNetworks, contains) {
+		return nil
+	}
+	if slices.ContainsFunc(o.DeniedNetworks, contains) {
+		return &DestinationBlockedError{Destination: address, Reason: "address is in a denied network"}
+	}
+	return nil
+}
+
+// Control is set as [net.Dialer.Control].
+func (o *ssrfOptions) Control(network, address string, _ syscall.RawConn) error {
+	return o.checkAddress(network, address)
+}
+
+// CheckRedirect is set as [http.Client.CheckRedirect].
+func (o *ssrfOptions) CheckRedirect(r *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return o.checkURL(r.URL)
+}
+
+type ssrfTransport struct {
+	next http.RoundTripper
+	*ssrfOptions
+}
+
+func (t *ssrfTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if err := t.checkURL(r.URL); err != nil {
+		if r.Body != nil {
+			_ = r.Body.Close() // round trippers must close the body
+		}
+		return nil, err
+	}
+	return t.next.RoundTrip(r)
+}
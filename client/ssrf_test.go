@@ -0,0 +1,89 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+func TestSSRFProtection(t *testing.T) {
+	var redirect string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if redirect != "" {
+			http.Redirect(w, r, redirect, http.StatusFound)
+		}
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.ParseUint(serverURL.Port(), 10, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	guarded, err := New(WithSSRFProtection())
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowed, err := New(WithSSRFProtection(
+		WithAllowedNetworks("127.0.0.1/32"),
+		WithAllowedPorts(uint16(port), 80),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name     string
+		Client   *http.Client
+		URL      string
+		Redirect string
+		Blocked  bool
+	}{
+		{Name: "port", Client: guarded, URL: server.URL, Blocked: true},
+		{Name: "loopback", Client: guarded, URL: "http://127.0.0.1/", Blocked: true},
+		{Name: "metadata", Client: guarded, URL: "http://169.254.169.254/latest/meta-data/", Blocked: true},
+		{Name: "mapped", Client: guarded, URL: "http://[::ffff:10.0.0.1]/", Blocked: true},
+		{Name: "scheme", Client: guarded, URL: "ftp://example.com/", Blocked: true},
+		{Name: "allowed", Client: allowed, URL: server.URL},
+		{Name: "redirect to metadata", Client: allowed, URL: server.URL, Redirect: "http://169.254.169.254/", Blocked: true},
+		{Name: "redirect to port", Client: allowed, URL: server.URL, Redirect: "http://127.0.0.1:22/", Blocked: true},
+		{Name: "redirect to scheme", Client: allowed, URL: server.URL, Redirect: "gopher://127.0.0.1/", Blocked: true},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			redirect = c.Redirect
+			response, err := c.Client.Get(c.URL)
+			if err == nil {
+				_ = response.Body.Close()
+			}
+			var blocked *DestinationBlockedError
+			if c.Blocked {
+				if !errors.As(err, &blocked) || !errors.Is(err, ErrDestinationBlocked) {
+					t.Fatal("request was not blocked:", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestSSRFOptions(t *testing.T) {
+	if _, err := New(WithSSRFProtection(WithDeniedNetworks("10.0.0.0/33"))); err == nil {
+		t.Fatal("invalid network was accepted")
+	}
+	if _, err := New(WithSSRFProtection(WithAllowedSchemes("file"))); err == nil {
+		t.Fatal("unsupported scheme was accepted")
+	}
+	if _, err := New(WithSSRFProtection(WithAllowedPorts(0))); err == nil {
+		t.Fatal("port 0 was accepted")
+	}
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/dkotik/oakhttp/internal/tracecontext"
+)
+
+type traceContextTransport struct {
+	next http.RoundTripper
+}
+
+// NewTraceContextTransport injects W3C traceparent and tracestate headers into outgoing requests whose context carries an trace context set by [oakhttp.NewTraceContextMiddleware]. The current span becomes the parent of the downstream request.
+func NewTraceContextTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &traceContextTransport{next: next}
+}
+
+func (t *traceContextTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	tc, ok := tracecontext.FromContext(r.Context())
+	if !ok {
+		return t.next.RoundTrip(r)
+	}
+	r = r.Clone(r.Context()) // round trippers must not modify the original request
+	r.Header.Set(tracecontext.TraceParentHeader, tc.TraceParent())
+	if tc.State != "" {
+		r.Header.Set(tracecontext.TraceStateHeader, tc.State)
+	} else {
+		r.Header.Del(tracecontext.TraceStateHeader)
+	}
+	return t.next.RoundTrip(r)
+}
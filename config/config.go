@@ -0,0 +1,181 @@
+/*
+Package config fills functional options from environment variables and configuration files, so that every component is configured the same way. Values are validated by the same option functions that set them in code, and all invalid fields are reported at once.
+
+	err := server.Run(
+	  ctx,
+	  server.WithHandler(mux),
+	  server.WithEnvironment("MYAPP"), // MYAPP_PORT=8443, MYAPP_READ_TIMEOUT=5s, ...
+	)
+
+Configuration files are JSON objects with the same field names in lower case:
+
+	{
+	  "port": 8443,
+	  "read_timeout": "5s",
+	  "tls_certificate_file": "/etc/myapp/cert.pem",
+	  "tls_key_file": "/etc/myapp/key.pem"
+	}
+*/
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Source looks up raw values of configuration fields by their lower case snake names, like "read_timeout".
+type Source interface {
+	Lookup(field string) (value string, ok bool)
+	// Describe names the field the way the operator sees it in error messages.
+	Describe(field string) string
+}
+
+type environment struct {
+	prefix string
+}
+
+// Environment looks up fields in environment variables named with upper case prefix and field name separated by underscore. Variables set to an empty value are ignored.
+func Environment(prefix string) Source {
+	prefix = strings.ToUpper(strings.TrimSuffix(prefix, "_"))
+	if prefix != "" {
+		prefix += "_"
+	}
+	return environment{prefix: prefix}
+}
+
+func (e environment) variable(field string) string {
+	return e.prefix + strings.ToUpper(field)
+}
+
+func (e environment) Lookup(field string) (string, bool) {
+	value := strings.TrimSpace(os.Getenv(e.variable(field)))
+	return value, value != ""
+}
+
+func (e environment) Describe(field string) string {
+	return "environment variable " + e.variable(field)
+}
+
+type file struct {
+	path   string
+	values map[string]string
+}
+
+// File reads a JSON object with string, number, and boolean values. Null values are ignored.
+func File(path string) (Source, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read configuration file: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	raw := make(map[string]any)
+	if err = decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("cannot decode configuration file %q: %w", path, err)
+	}
+
+	f := file{path: path, values: make(map[string]string, len(raw))}
+	for key, value := range raw {
+		switch value := value.(type) {
+		case nil:
+		case string:
+			f.values[strings.ToLower(key)] = strings.TrimSpace(value)
+		case json.Number:
+			f.values[strings.ToLower(key)] = value.String()
+		case bool:
+			f.values[strings.ToLower(key)] = fmt.Sprint(value)
+		default:
+			err = errors.Join(err, &FieldError{
+				Field: f.Describe(key),
+				Err:   fmt.Errorf("unsupported value type %T", value),
+			})
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f file) Lookup(field string) (string, bool) {
+	value, ok := f.values[field]
+	return value, ok
+}
+
+func (f file) Describe(field string) string {
+	return fmt.Sprintf("field %q of configuration file %q", field, f.path)
+}
+
+// Fields lists all fields present in the file, so that unknown fields can be reported.
+func (f file) Fields() []string {
+	fields := make([]string, 0, len(f.values))
+	for field := range f.values {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+// FieldError reports an invalid configuration field.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return "invalid " + e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Apply looks up every field in the source and applies the values that are present to the target options. Returns all errors joined together. Fields in a configuration file that none of the given fields recognize are reported as errors to catch typos.
+func Apply[T any](target *T, source Source, fields ...Field[T]) (err error) {
+	if source == nil {
+		return errors.New("cannot use a <nil> configuration source")
+	}
+	known := make(map[string]struct{})
+	for _, field := range fields {
+		values := make([]string, len(field.Names))
+		present := false
+		for i, name := range field.Names {
+			known[name] = struct{}{}
+			value, ok := source.Lookup(name)
+			if ok {
+				values[i] = value
+				present = true
+			}
+		}
+		if !present {
+			continue
+		}
+		if fieldErr := field.apply(target, values); fieldErr != nil {
+			descriptions := make([]string, len(field.Names))
+			for i, name := range field.Names {
+				descriptions[i] = source.Describe(name)
+			}
+			err = errors.Join(err, &FieldError{
+				Field: strings.Join(descriptions, " and "),
+				Err:   fieldErr,
+			})
+		}
+	}
+
+	if listed, ok := source.(interface{ Fields() []string }); ok {
+		for _, name := range listed.Fields() {
+			if _, ok := known[name]; !ok {
+				err = errors.Join(err, &FieldError{
+					Field: source.Describe(name),
+					Err:   errors.New("unknown field"),
+				})
+			}
+		}
+	}
+	return err
+}
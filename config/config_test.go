@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testOptions struct {
+	Name    string
+	Timeout time.Duration
+	Limit   int
+	Enabled bool
+	Cert    string
+	Key     string
+}
+
+type testOption func(*testOptions) error
+
+func withName(name string) testOption {
+	return func(o *testOptions) error {
+		if name == "invalid" {
+			return errors.New("name is invalid")
+		}
+		o.Name = name
+		return nil
+	}
+}
+
+func withTimeout(d time.Duration) testOption {
+	return func(o *testOptions) error {
+		if d > time.Minute {
+			return errors.New("timeout is too long")
+		}
+		o.Timeout = d
+		return nil
+	}
+}
+
+func withLimit(n int) testOption {
+	return func(o *testOptions) error {
+		o.Limit = n
+		return nil
+	}
+}
+
+func withEnabled() testOption {
+	return func(o *testOptions) error {
+		o.Enabled = true
+		return nil
+	}
+}
+
+func withKeyPair(cert, key string) testOption {
+	return func(o *testOptions) error {
+		o.Cert, o.Key = cert, key
+		return nil
+	}
+}
+
+var testFields = []Field[testOptions]{
+	String("name", withName),
+	Duration("timeout", withTimeout),
+	Int("limit", withLimit),
+	Bool("enabled", withEnabled),
+	Pair("cert", "key", withKeyPair),
+}
+
+func TestEnvironment(t *testing.T) {
+	t.Setenv("TEST_NAME", "service")
+	t.Setenv("TEST_TIMEOUT", "5s")
+	t.Setenv("TEST_ENABLED", "true")
+	t.Setenv("TEST_CERT", "cert.pem")
+	t.Setenv("TEST_KEY", "key.pem")
+	t.Setenv("TEST_LIMIT", "")
+
+	o := &testOptions{}
+	if err := Apply(o, Environment("TEST_"), testFields...); err != nil {
+		t.Fatal(err)
+	}
+	expected := testOptions{Name: "service", Timeout: time.Second * 5, Enabled: true, Cert: "cert.pem", Key: "key.pem"}
+	if *o != expected {
+		t.Fatalf("unexpected options: %+v", *o)
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"name":"service","limit":12,"enabled":false,"timeout":null}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	source, err := File(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := &testOptions{}
+	if err = Apply(o, source, testFields...); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (testOptions{Name: "service", Limit: 12}); *o != expected {
+		t.Fatalf("unexpected options: %+v", *o)
+	}
+}
+
+func TestAllErrorsReported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{
+		"name": "invalid",
+		"timeout": "2h",
+		"limit": "many",
+		"enabled": "sometimes",
+		"cert": "cert.pem",
+		"tiemout": "1s"
+	}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	source, err := File(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Apply(&testOptions{}, source, testFields...)
+	if err == nil {
+		t.Fatal("invalid configuration was accepted")
+	}
+	for _, expected := range []string{
+		`field "name"`,
+		`field "timeout"`,
+		`field "limit"`,
+		`field "enabled"`,
+		`field "cert"`,
+		`field "tiemout"`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("error for %s is missing from:\n%s", expected, err)
+		}
+	}
+	var fieldError *FieldError
+	if !errors.As(err, &fieldError) {
+		t.Fatal("field errors are not exposed")
+	}
+}
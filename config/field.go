@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Field binds one or more configuration values to an option constructor.
+type Field[T any] struct {
+	Names []string
+	apply func(target *T, values []string) error
+}
+
+// Func binds values of several fields to a custom function. Missing values are empty strings. The function is called when at least one of the fields is present.
+func Func[T any](apply func(target *T, values []string) error, names ...string) Field[T] {
+	if apply == nil {
+		panic("cannot use a <nil> configuration function")
+	}
+	if len(names) == 0 {
+		panic("configuration field requires a name")
+	}
+	return Field[T]{Names: names, apply: apply}
+}
+
+func String[T any, O ~func(*T) error](name string, option func(string) O) Field[T] {
+	return Func(func(target *T, values []string) error {
+		return option(values[0])(target)
+	}, name)
+}
+
+// Duration parses values like "1m30s" using [time.ParseDuration].
+func Duration[T any, O ~func(*T) error](name string, option func(time.Duration) O) Field[T] {
+	return Func(func(target *T, values []string) error {
+		d, err := time.ParseDuration(values[0])
+		if err != nil {
+			return fmt.Errorf("cannot parse duration: %w", err)
+		}
+		return option(d)(target)
+	}, name)
+}
+
+func Int[T any, O ~func(*T) error](name string, option func(int) O) Field[T] {
+	return Func(func(target *T, values []string) error {
+		n, err := strconv.Atoi(values[0])
+		if err != nil {
+			return errors.New("value must be an integer")
+		}
+		return option(n)(target)
+	}, name)
+}
+
+// Bool applies the option when the value is true and ignores it when the value is false.
+func Bool[T any, O ~func(*T) error](name string, option func() O) Field[T] {
+	return Func(func(target *T, values []string) error {
+		enabled, err := strconv.ParseBool(values[0])
+		if err != nil {
+			return errors.New("value must be a boolean")
+		}
+		if !enabled {
+			return nil
+		}
+		return option()(target)
+	}, name)
+}
+
+// Pair applies the option with two values that must be set together, like certificate and key files.
+func Pair[T any, O ~func(*T) error](first, second string, option func(string, string) O) Field[T] {
+	return Func(func(target *T, values []string) error {
+		if values[0] == "" || values[1] == "" {
+			return errors.New("both values must be set together")
+		}
+		return option(values[0], values[1])(target)
+	}, first, second)
+}
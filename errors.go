@@ -2,6 +2,7 @@ package oakhttp
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
@@ -10,6 +11,7 @@ import (
 	"maps"
 	"net/http"
 	"slices"
+	"strings"
 
 	"github.com/dkotik/oakhttp/internal/msg"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
@@ -49,12 +51,15 @@ func (e Error) LogValue() slog.Value {
 }
 
 type LocalizedError struct {
-	LanguageTag   string
-	StatusCode    int
-	KnowledgeCode string
-	Title         string
-	Description   string
-	Message       string
+	LanguageTag   string `json:"language,omitempty"`
+	StatusCode    int    `json:"status"`
+	KnowledgeCode string `json:"knowledgeCode,omitempty"`
+	Title         string `json:"title"`
+	Description   string `json:"description,omitempty"`
+	Message       string `json:"message,omitempty"`
+	TraceID       string `json:"traceID,omitempty"`
+	// Fields lists invalid request fields reported by [ValidationError].
+	Fields []LocalizedFieldError `json:"fields,omitempty"`
 	// Cause       func() string
 }
 
@@ -80,6 +85,15 @@ func (e Error) Localize(lc *i18n.Localizer) (localized LocalizedError, err error
 	return localized, nil
 }
 
+// rendersLike is true when both errors localize to the same response.
+func (e Error) rendersLike(another Error) bool {
+	return e.StatusCode == another.StatusCode &&
+		e.KnowledgeCode == another.KnowledgeCode &&
+		e.Title == another.Title &&
+		e.Description == another.Description &&
+		e.Message == another.Message
+}
+
 type ErrorWithStatusCode interface {
 	error
 	GetHyperTextStatusCode() int
@@ -109,6 +123,14 @@ func (f ErrorRendererFunc) RenderError(w io.Writer, err LocalizedError) error {
 	return f(w, err)
 }
 
+// Media types of the built-in [ErrorRenderer]s.
+const (
+	MediaTypeHTML        = "text/html"
+	MediaTypeProblemJSON = "application/problem+json"
+	MediaTypeJSON        = "application/json"
+	MediaTypeText        = "text/plain"
+)
+
 func NewErrorRenderer(t *template.Template) ErrorRenderer {
 	if t == nil {
 		et, err := Templates.ReadFile("internal/templates/page/error.html")
@@ -125,15 +147,193 @@ func NewErrorRenderer(t *template.Template) ErrorRenderer {
 	})
 }
 
+// NewProblemErrorRenderer encodes errors as RFC 9457 problem details. [LocalizedError.KnowledgeCode] becomes the problem type.
+func NewProblemErrorRenderer() ErrorRenderer {
+	return ErrorRendererFunc(func(w io.Writer, err LocalizedError) error {
+		problemType := err.KnowledgeCode
+		if problemType == "" {
+			problemType = "about:blank"
+		}
+		detail := err.Message
+		if detail == "" {
+			detail = err.Description
+		}
+		return json.NewEncoder(w).Encode(struct {
+			Type     string                `json:"type"`
+			Title    string                `json:"title"`
+			Status   int                   `json:"status"`
+			Detail   string                `json:"detail,omitempty"`
+			TraceID  string                `json:"traceID,omitempty"`
+			Language string                `json:"language,omitempty"`
+			Errors   []LocalizedFieldError `json:"errors,omitempty"`
+		}{
+			Type:     problemType,
+			Title:    err.Title,
+			Status:   err.StatusCode,
+			Detail:   detail,
+			TraceID:  err.TraceID,
+			Language: err.LanguageTag,
+			Errors:   err.Fields,
+		})
+	})
+}
+
+// NewJSONErrorRenderer encodes [LocalizedError] as a JSON object.
+func NewJSONErrorRenderer() ErrorRenderer {
+	return ErrorRendererFunc(func(w io.Writer, err LocalizedError) error {
+		return json.NewEncoder(w).Encode(struct {
+			Error LocalizedError `json:"error"`
+		}{
+			Error: err,
+		})
+	})
+}
+
+// NewTextErrorRenderer writes [LocalizedError] as human-readable plain text.
+func NewTextErrorRenderer() ErrorRenderer {
+	return ErrorRendererFunc(func(w io.Writer, err LocalizedError) error {
+		b := &bytes.Buffer{}
+		b.WriteString(err.Title)
+		if err.KnowledgeCode != "" {
+			b.WriteString(" - ")
+			b.WriteString(err.KnowledgeCode)
+		}
+		b.WriteString("\n\n")
+		if err.Message != "" {
+			b.WriteString(err.Message)
+			b.WriteString("\n")
+		}
+		if err.Description != "" && err.Description != err.Message {
+			b.WriteString(err.Description)
+			b.WriteString("\n")
+		}
+		if len(err.Fields) > 0 {
+			b.WriteString("\n")
+			for _, field := range err.Fields {
+				b.WriteString("- ")
+				b.WriteString(field.Path)
+				b.WriteString(": ")
+				b.WriteString(field.Message)
+				b.WriteString("\n")
+			}
+		}
+		if err.TraceID != "" {
+			b.WriteString("\nTrace ID: ")
+			b.WriteString(err.TraceID)
+			b.WriteString("\n")
+		}
+		_, writeErr := io.Copy(w, b)
+		return writeErr
+	})
+}
+
+// NewErrorRenderersByMediaType returns the built-in [ErrorRenderer]s keyed by media type. The HTML renderer is used for "text/html". It defaults to [NewErrorRenderer] when <nil>.
+func NewErrorRenderersByMediaType(html ErrorRenderer) map[string]ErrorRenderer {
+	if html == nil {
+		html = NewErrorRenderer(nil)
+	}
+	return map[string]ErrorRenderer{
+		MediaTypeHTML:        html,
+		MediaTypeProblemJSON: NewProblemErrorRenderer(),
+		MediaTypeJSON:        NewJSONErrorRenderer(),
+		MediaTypeText:        NewTextErrorRenderer(),
+	}
+}
+
+func contentTypeHeader(mediaType string) string {
+	if strings.HasPrefix(mediaType, "text/") {
+		return mediaType + "; charset=utf-8"
+	}
+	return mediaType
+}
+
+// traceIDPlaceholder locates the trace ID in pre-rendered content.
+const traceIDPlaceholder = "oakhttpTraceIDPlaceholder"
+
 type staticErrorHandler struct {
-	StatusCode int
-	Content    []byte
-	Logger     *slog.Logger
+	StatusCode  int
+	ContentType string
+	Content     []byte // rendered without the trace ID
+	Traced      [][]byte
+	Localized   LocalizedError
+	Renderer    ErrorRenderer // <nil> when the renderer omits the trace ID
+	Logger      *slog.Logger
+}
+
+func newStaticErrorHandler(
+	r ErrorRenderer,
+	localized LocalizedError,
+	contentType string,
+	logger *slog.Logger,
+) (eh staticErrorHandler, err error) {
+	b := &bytes.Buffer{}
+	if err = r.RenderError(b, localized); err != nil {
+		return eh, err
+	}
+	eh = staticErrorHandler{
+		StatusCode:  localized.StatusCode,
+		ContentType: contentType,
+		Content:     b.Bytes(),
+		Logger:      logger,
+	}
+
+	traced := localized
+	traced.TraceID = traceIDPlaceholder
+	b = &bytes.Buffer{}
+	if err = r.RenderError(b, traced); err != nil {
+		return eh, err
+	}
+	if bytes.Equal(b.Bytes(), eh.Content) {
+		return eh, nil
+	}
+	eh.Localized = localized
+	eh.Renderer = r
+	before, after, ok := bytes.Cut(b.Bytes(), []byte(traceIDPlaceholder))
+	if ok && !bytes.Contains(after, []byte(traceIDPlaceholder)) {
+		eh.Traced = [][]byte{before, after}
+	}
+	return eh, nil
+}
+
+// isVerbatimTraceID is true for trace IDs that all renderers output without escaping.
+func isVerbatimTraceID(ID string) bool {
+	for _, c := range ID {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (eh staticErrorHandler) content(ID string) []byte {
+	if ID == "" {
+		return eh.Content
+	}
+	if len(eh.Traced) == 2 && isVerbatimTraceID(ID) {
+		content := make([]byte, 0, len(eh.Traced[0])+len(ID)+len(eh.Traced[1]))
+		content = append(content, eh.Traced[0]...)
+		content = append(content, ID...)
+		return append(content, eh.Traced[1]...)
+	}
+	localized := eh.Localized
+	localized.TraceID = ID
+	b := &bytes.Buffer{}
+	if err := eh.Renderer.RenderError(b, localized); err != nil {
+		return eh.Content
+	}
+	return b.Bytes()
 }
 
 func (eh staticErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	content := eh.Content
+	if eh.Renderer != nil {
+		content = eh.content(TraceIDFromContext(r.Context()))
+	}
+	w.Header().Set("Content-Type", eh.ContentType)
 	w.WriteHeader(eh.StatusCode)
-	_, _ = io.Copy(w, bytes.NewReader(eh.Content))
+	_, _ = io.Copy(w, bytes.NewReader(content))
 	eh.Logger.Log(
 		r.Context(),
 		slog.LevelError,
@@ -150,14 +350,33 @@ func (eh staticErrorHandler) HandleError(w http.ResponseWriter, r *http.Request,
 type errorHandler struct {
 	LocalizerBundle *i18n.Bundle
 	Renderer        ErrorRenderer
+	ContentType     string
 	Logger          *slog.Logger
 }
 
+// NewErrorHandler creates an [ErrorHandler] that negotiates the response format using the Accept header. The provided [ErrorRenderer] is used for HTML pages, while the rest of the formats are covered by [NewErrorRenderersByMediaType]. Static errors are pre-rendered for every language and media type. The trace ID is spliced into the pre-rendered content of formats that include it.
 func NewErrorHandler(
 	localizationBunble *i18n.Bundle,
 	r ErrorRenderer,
 	logger *slog.Logger,
 	static ...Error,
+) ErrorHandler {
+	return NewErrorHandlerForMediaTypes(
+		localizationBunble,
+		NewErrorRenderersByMediaType(r),
+		MediaTypeHTML,
+		logger,
+		static...,
+	)
+}
+
+// NewErrorHandlerForMediaTypes creates an [ErrorHandler] that picks one of the [ErrorRenderer]s by matching the Accept header against their media types. The fallback media type is used when the Accept header is missing or cannot be satisfied.
+func NewErrorHandlerForMediaTypes(
+	localizationBunble *i18n.Bundle,
+	renderers map[string]ErrorRenderer,
+	fallback string,
+	logger *slog.Logger,
+	static ...Error,
 ) ErrorHandler {
 	if localizationBunble == nil {
 		// TODO: replace with package level bundle
@@ -167,21 +386,50 @@ func NewErrorHandler(
 	if len(languages) == 0 {
 		panic("localization bundle contains zero translation languages")
 	}
-	if r == nil {
-		r = NewErrorRenderer(nil)
+	if len(renderers) == 0 {
+		panic("zero error renderers provided")
 	}
 	if logger == nil {
 		logger = slog.Default()
 	}
-	if len(static) == 0 {
-		return errorHandler{
-			LocalizerBundle: localizationBunble,
-			Renderer:        r,
-			Logger:          logger,
+
+	byMediaType := make(map[string]ErrorHandler)
+	for mediaType, r := range renderers {
+		if r == nil {
+			panic(fmt.Sprintf("error renderer for media type %q is <nil>", mediaType))
 		}
+		byMediaType[mediaType] = newErrorHandlerForMediaType(
+			localizationBunble,
+			languages,
+			r,
+			contentTypeHeader(mediaType),
+			logger,
+			static,
+		)
+	}
+	return NewErrorHandlerSwitchByMediaType(byMediaType, fallback)
+}
+
+func newErrorHandlerForMediaType(
+	localizationBunble *i18n.Bundle,
+	languages []language.Tag,
+	r ErrorRenderer,
+	contentType string,
+	logger *slog.Logger,
+	static []Error,
+) ErrorHandler {
+	dynamic := errorHandler{
+		LocalizerBundle: localizationBunble,
+		Renderer:        r,
+		ContentType:     contentType,
+		Logger:          logger,
+	}
+	if len(static) == 0 {
+		return dynamic
 	}
 
 	byStatusCode := make(map[int]ErrorHandler)
+	registered := make(map[int]Error)
 	var (
 		statusCode int
 		ok         bool
@@ -192,6 +440,7 @@ func NewErrorHandler(
 		if _, ok = byStatusCode[statusCode]; ok {
 			panic(fmt.Sprintf("error with status code %d occurs twice", statusCode))
 		}
+		registered[statusCode] = errWithStatusCode
 
 		byLanguageTag := make(map[language.Tag]ErrorHandler)
 		for _, lang := range languages {
@@ -199,25 +448,30 @@ func NewErrorHandler(
 			if err != nil {
 				panic(fmt.Errorf("unable to localize error: %w", err))
 			}
-			b := &bytes.Buffer{}
-			if err = r.RenderError(b, localized); err != nil {
+			byLanguageTag[lang], err = newStaticErrorHandler(r, localized, contentType, logger)
+			if err != nil {
 				panic(fmt.Errorf("unable to render a localized error: %w", err))
 			}
-			byLanguageTag[lang] = staticErrorHandler{
-				StatusCode: statusCode,
-				Content:    b.Bytes(),
-			}
 		}
 		byStatusCode[statusCode] = NewErrorHandlerSwitchByLanguage(byLanguageTag, language.PreferSameScript(true))
 	}
 	if _, ok = byStatusCode[http.StatusInternalServerError]; !ok {
-		byStatusCode[http.StatusInternalServerError] = errorHandler{
-			LocalizerBundle: localizationBunble,
-			Renderer:        r,
-			Logger:          logger,
-		}
+		byStatusCode[http.StatusInternalServerError] = dynamic
 	}
-	return NewErrorHandlerSwitchByStatusCode(byStatusCode)
+	pregenerated := NewErrorHandlerSwitchByStatusCode(byStatusCode)
+	return ErrorHandlerFunc(func(w http.ResponseWriter, r *http.Request, err error) {
+		var validationError ValidationError
+		if errors.As(err, &validationError) {
+			dynamic.HandleError(w, r, err) // field errors cannot be pre-rendered
+			return
+		}
+		var coded Error
+		if errors.As(err, &coded) && !coded.rendersLike(registered[coded.StatusCode]) {
+			dynamic.HandleError(w, r, err) // knowledge code or message differ from the pre-rendered error
+			return
+		}
+		pregenerated.HandleError(w, r, err)
+	})
 }
 
 func (h errorHandler) HandleError(w http.ResponseWriter, r *http.Request, err error) {
@@ -233,13 +487,18 @@ func (h errorHandler) HandleError(w http.ResponseWriter, r *http.Request, err er
 		),
 	)
 
-	var localizableError Error
+	var localizableError LocalizableError
 	if !errors.As(err, &localizableError) {
 		localizableError = NewError(err, "")
 	}
-	w.WriteHeader(localizableError.StatusCode)
-	lc := i18n.NewLocalizer(h.LocalizerBundle, r.Header.Get("Accept-Language"))
+	languages := requestLanguages(r)
+	preferred := make([]string, len(languages))
+	for i, tag := range languages {
+		preferred[i] = tag.String()
+	}
+	lc := i18n.NewLocalizer(h.LocalizerBundle, preferred...)
 	localized, err := localizableError.Localize(lc)
+	w.Header().Set("Content-Type", h.ContentType)
 	if err != nil {
 		// panic(e)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -256,6 +515,8 @@ func (h errorHandler) HandleError(w http.ResponseWriter, r *http.Request, err er
 		)
 		return
 	}
+	localized.TraceID = TraceIDFromContext(r.Context())
+	w.WriteHeader(localized.StatusCode)
 
 	if err = h.Renderer.RenderError(w, localized); err != nil {
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -300,7 +561,7 @@ func (eh ehByStatusCode) HandleError(w http.ResponseWriter, r *http.Request, err
 
 type ehByLanguage struct {
 	Matcher  language.Matcher
-	Handlers map[language.Tag]ErrorHandler
+	Handlers []ErrorHandler
 }
 
 func NewErrorHandlerSwitchByLanguage(sw map[language.Tag]ErrorHandler, options ...language.MatchOption) ErrorHandler {
@@ -308,24 +569,66 @@ func NewErrorHandlerSwitchByLanguage(sw map[language.Tag]ErrorHandler, options .
 		panic("empty error handler switch")
 	}
 
+	tags := slices.SortedFunc(maps.Keys(sw), func(a, b language.Tag) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	handlers := make([]ErrorHandler, len(tags))
+	for i, tag := range tags {
+		handlers[i] = sw[tag]
+	}
 	return ehByLanguage{
-		Matcher:  language.NewMatcher(slices.Collect(maps.Keys(sw)), options...),
-		Handlers: maps.Clone(sw),
+		Matcher:  language.NewMatcher(tags, options...),
+		Handlers: handlers,
 	}
 }
 
 func (eh ehByLanguage) HandleError(w http.ResponseWriter, r *http.Request, err error) {
-	tag, _, _ := eh.Matcher.Match(language.Make(r.Header.Get("Accept-Language")))
-	eh.Handlers[tag].HandleError(w, r, err)
+	// matcher returns a tag with extensions, index is reliable
+	_, index, _ := eh.Matcher.Match(requestLanguages(r)...)
+	eh.Handlers[index].HandleError(w, r, err)
+}
+
+type ehByMediaType struct {
+	MediaTypes []string
+	Handlers   map[string]ErrorHandler
+}
+
+// NewErrorHandlerSwitchByMediaType selects an [ErrorHandler] using [NegotiateMediaType]. The fallback handler is used when the Accept header cannot be satisfied.
+func NewErrorHandlerSwitchByMediaType(sw map[string]ErrorHandler, fallback string) ErrorHandler {
+	if len(sw) == 0 {
+		panic("empty error handler switch")
+	}
+	if _, ok := sw[fallback]; !ok {
+		panic(fmt.Sprintf("default media type handler is missing: %s", fallback))
+	}
+
+	mediaTypes := slices.Sorted(maps.Keys(sw))
+	mediaTypes = slices.DeleteFunc(mediaTypes, func(mediaType string) bool {
+		return mediaType == fallback
+	})
+	return ehByMediaType{
+		MediaTypes: append([]string{fallback}, mediaTypes...),
+		Handlers:   maps.Clone(sw),
+	}
+}
+
+func (eh ehByMediaType) HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Add("Vary", "Accept")
+	mediaType := NegotiateMediaType(r.Header.Get("Accept"), eh.MediaTypes...)
+	if mediaType == "" {
+		mediaType = eh.MediaTypes[0]
+	}
+	eh.Handlers[mediaType].HandleError(w, r, err)
 }
 
 func NewError(from error, knowledgeCode string) Error {
 	return Error{
-		StatusCode:  http.StatusInternalServerError,
-		Title:       msg.ErrorInternalTitle,
-		Description: msg.ErrorInternalDescription,
-		Message:     msg.ErrorInternalDescription,
-		Cause:       from,
+		StatusCode:    http.StatusInternalServerError,
+		KnowledgeCode: knowledgeCode,
+		Title:         msg.ErrorInternalTitle,
+		Description:   msg.ErrorInternalDescription,
+		Message:       msg.ErrorInternalDescription,
+		Cause:         from,
 	}
 }
 
@@ -350,3 +653,144 @@ func NewAccessDeniedError(from error, knowledgeCode string) Error {
 		Cause:         from,
 	}
 }
+
+func NewBadRequestError(from error, knowledgeCode string) Error {
+	return Error{
+		StatusCode:    http.StatusBadRequest,
+		KnowledgeCode: knowledgeCode,
+		Title:         msg.ErrorBadRequestTitle,
+		Description:   msg.ErrorBadRequestDescription,
+		Message:       msg.ErrorBadRequestDescription,
+		Cause:         from,
+	}
+}
+
+func NewUnauthorizedError(from error, knowledgeCode string) Error {
+	return Error{
+		StatusCode:    http.StatusUnauthorized,
+		KnowledgeCode: knowledgeCode,
+		Title:         msg.ErrorUnauthorizedTitle,
+		Description:   msg.ErrorUnauthorizedDescription,
+		Message:       msg.ErrorUnauthorizedDescription,
+		Cause:         from,
+	}
+}
+
+func NewMethodNotAllowedError(from error, knowledgeCode string) Error {
+	return Error{
+		StatusCode:    http.StatusMethodNotAllowed,
+		KnowledgeCode: knowledgeCode,
+		Title:         msg.ErrorMethodNotAllowedTitle,
+		Description:   msg.ErrorMethodNotAllowedDescription,
+		Message:       msg.ErrorMethodNotAllowedDescription,
+		Cause:         from,
+	}
+}
+
+func NewConflictError(from error, knowledgeCode string) Error {
+	return Error{
+		StatusCode:    http.StatusConflict,
+		KnowledgeCode: knowledgeCode,
+		Title:         msg.ErrorConflictTitle,
+		Description:   msg.ErrorConflictDescription,
+		Message:       msg.ErrorConflictDescription,
+		Cause:         from,
+	}
+}
+
+func NewGoneError(from error, knowledgeCode string) Error {
+	return Error{
+		StatusCode:    http.StatusGone,
+		KnowledgeCode: knowledgeCode,
+		Title:         msg.ErrorGoneTitle,
+		Description:   msg.ErrorGoneDescription,
+		Message:       msg.ErrorGoneDescription,
+		Cause:         from,
+	}
+}
+
+func NewRequestEntityTooLargeError(from error, knowledgeCode string) Error {
+	return Error{
+		StatusCode:    http.StatusRequestEntityTooLarge,
+		KnowledgeCode: knowledgeCode,
+		Title:         msg.ErrorRequestEntityTooLargeTitle,
+		Description:   msg.ErrorRequestEntityTooLargeDescription,
+		Message:       msg.ErrorRequestEntityTooLargeDescription,
+		Cause:         from,
+	}
+}
+
+func NewUnsupportedMediaTypeError(from error, knowledgeCode string) Error {
+	return Error{
+		StatusCode:    http.StatusUnsupportedMediaType,
+		KnowledgeCode: knowledgeCode,
+		Title:         msg.ErrorUnsupportedMediaTypeTitle,
+		Description:   msg.ErrorUnsupportedMediaTypeDescription,
+		Message:       msg.ErrorUnsupportedMediaTypeDescription,
+		Cause:         from,
+	}
+}
+
+func NewUnprocessableEntityError(from error, knowledgeCode string) Error {
+	return Error{
+		StatusCode:    http.StatusUnprocessableEntity,
+		KnowledgeCode: knowledgeCode,
+		Title:         msg.ErrorValidationTitle,
+		Description:   msg.ErrorValidationDescription,
+		Message:       msg.ErrorValidationDescription,
+		Cause:         from,
+	}
+}
+
+func NewTooManyRequestsError(from error, knowledgeCode string) Error {
+	return Error{
+		StatusCode:    http.StatusTooManyRequests,
+		KnowledgeCode: knowledgeCode,
+		Title:         msg.ErrorTooManyRequestsTitle,
+		Description:   msg.ErrorTooManyRequestsDescription,
+		Message:       msg.ErrorTooManyRequestsDescription,
+		Cause:         from,
+	}
+}
+
+func NewServiceUnavailableError(from error, knowledgeCode string) Error {
+	return Error{
+		StatusCode:    http.StatusServiceUnavailable,
+		KnowledgeCode: knowledgeCode,
+		Title:         msg.ErrorServiceUnavailableTitle,
+		Description:   msg.ErrorServiceUnavailableDescription,
+		Message:       msg.ErrorServiceUnavailableDescription,
+		Cause:         from,
+	}
+}
+
+func NewGatewayTimeoutError(from error, knowledgeCode string) Error {
+	return Error{
+		StatusCode:    http.StatusGatewayTimeout,
+		KnowledgeCode: knowledgeCode,
+		Title:         msg.ErrorGatewayTimeoutTitle,
+		Description:   msg.ErrorGatewayTimeoutDescription,
+		Message:       msg.ErrorGatewayTimeoutDescription,
+		Cause:         from,
+	}
+}
+
+// NewStandardErrors returns one [Error] for each status code covered by the package constructors. Pass them to [NewErrorHandler] to pre-render all of them for every language of the localization bundle. Errors with a knowledge code or a message of their own are still rendered on demand.
+func NewStandardErrors() []Error {
+	return []Error{
+		NewBadRequestError(nil, ""),
+		NewUnauthorizedError(nil, ""),
+		NewAccessDeniedError(nil, ""),
+		NewNotFoundError(nil, ""),
+		NewMethodNotAllowedError(nil, ""),
+		NewConflictError(nil, ""),
+		NewGoneError(nil, ""),
+		NewRequestEntityTooLargeError(nil, ""),
+		NewUnsupportedMediaTypeError(nil, ""),
+		NewUnprocessableEntityError(nil, ""),
+		NewTooManyRequestsError(nil, ""),
+		NewError(nil, ""),
+		NewServiceUnavailableError(nil, ""),
+		NewGatewayTimeoutError(nil, ""),
+	}
+}
@@ -2,12 +2,16 @@ package oakhttp
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/sebdah/goldie/v2"
+	"golang.org/x/text/language"
 )
 
 func TestErrorHandler(t *testing.T) {
@@ -34,3 +38,205 @@ func TestErrorRendering(t *testing.T) {
 	g := goldie.New(t)
 	g.Assert(t, "errors/500", b.Bytes())
 }
+
+func TestErrorHandlerContentNegotiation(t *testing.T) {
+	eh := NewErrorHandler(nil, nil, nil, NewNotFoundError(nil, "missingPage"))
+
+	cases := []struct {
+		Accept      string
+		ContentType string
+	}{
+		{Accept: "", ContentType: "text/html; charset=utf-8"},
+		{Accept: "text/html,application/xhtml+xml,*/*;q=0.8", ContentType: "text/html; charset=utf-8"},
+		{Accept: "application/problem+json", ContentType: MediaTypeProblemJSON},
+		{Accept: "application/json, text/plain;q=0.5", ContentType: MediaTypeJSON},
+		{Accept: "text/plain", ContentType: "text/plain; charset=utf-8"},
+		{Accept: "image/png", ContentType: "text/html; charset=utf-8"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Accept, func(t *testing.T) {
+			for _, err := range []error{
+				errors.New("dynamic"),
+				NewNotFoundError(errors.New("static"), "missingPage"),
+			} {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.Header.Set("Accept", c.Accept)
+				eh.HandleError(w, r, err)
+				if contentType := w.Header().Get("Content-Type"); contentType != c.ContentType {
+					t.Fatalf("content type %q does not match %q", contentType, c.ContentType)
+				}
+			}
+		})
+	}
+}
+
+func TestProblemErrorRendering(t *testing.T) {
+	eh := NewErrorHandler(nil, nil, nil)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(ContextWithTraceID(r.Context(), "testTraceID"))
+	r.Header.Set("Accept", MediaTypeProblemJSON)
+	eh.HandleError(w, r, NewNotFoundError(errors.New("test"), "missingPage"))
+
+	var problem struct {
+		Type    string `json:"type"`
+		Title   string `json:"title"`
+		Status  int    `json:"status"`
+		Detail  string `json:"detail"`
+		TraceID string `json:"traceID"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusNotFound || problem.Status != http.StatusNotFound {
+		t.Fatal("unexpected status code:", w.Code, problem.Status)
+	}
+	if problem.Type != "missingPage" {
+		t.Fatal("knowledge code was not mapped to problem type:", problem.Type)
+	}
+	if problem.Title == "" || problem.Detail == "" {
+		t.Fatal("problem title or detail is empty")
+	}
+	if problem.TraceID != "testTraceID" {
+		t.Fatal("trace ID does not match:", problem.TraceID)
+	}
+}
+
+func TestStaticProblemErrorRendering(t *testing.T) {
+	eh := NewErrorHandler(nil, nil, nil, NewNotFoundError(nil, "missingPage"))
+
+	for _, traceID := range []string{"", "testTraceID", `test"Trace<ID>`} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if traceID != "" {
+			r = r.WithContext(ContextWithTraceID(r.Context(), traceID))
+		}
+		r.Header.Set("Accept", MediaTypeProblemJSON)
+		eh.HandleError(w, r, NewNotFoundError(errors.New("test"), "missingPage"))
+
+		var problem struct {
+			Type    string `json:"type"`
+			TraceID string `json:"traceID"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusNotFound || problem.Type != "missingPage" {
+			t.Fatal("unexpected pre-rendered error:", w.Code, problem.Type)
+		}
+		if problem.TraceID != traceID {
+			t.Fatalf("trace ID %q does not match %q", problem.TraceID, traceID)
+		}
+	}
+}
+
+func TestStaticErrorTraceIDSplicing(t *testing.T) {
+	localized, err := NewNotFoundError(nil, "missingPage").Localize(testLocalizer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for mediaType, r := range NewErrorRenderersByMediaType(nil) {
+		t.Run(mediaType, func(t *testing.T) {
+			eh, err := newStaticErrorHandler(r, localized, mediaType, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mediaType == MediaTypeHTML {
+				if eh.Renderer != nil {
+					t.Fatal("HTML page without a trace ID is rendered for every request")
+				}
+				return
+			}
+			if len(eh.Traced) != 2 {
+				t.Fatal("trace ID is not spliced into pre-rendered content")
+			}
+
+			for _, traceID := range []string{"", "0af7651916cd43dd8448eb211c80319c", `test"Trace<ID>`} {
+				traced := localized
+				traced.TraceID = traceID
+				b := &bytes.Buffer{}
+				if err = r.RenderError(b, traced); err != nil {
+					t.Fatal(err)
+				}
+				if content := eh.content(traceID); !bytes.Equal(content, b.Bytes()) {
+					t.Fatalf("pre-rendered content does not match:\n%s\nvs\n%s", content, b.Bytes())
+				}
+			}
+		})
+	}
+}
+
+func TestStandardErrorsKeepKnowledgeCodes(t *testing.T) {
+	eh := NewErrorHandler(nil, nil, nil, NewStandardErrors()...)
+
+	cases := []struct {
+		Error error
+		Type  string
+	}{
+		{Error: NewNotFoundError(errors.New("test"), ""), Type: "about:blank"},
+		{Error: NewNotFoundError(errors.New("test"), "userMissing"), Type: "userMissing"},
+		{Error: NewUnprocessableEntityError(errors.New("test"), "validationFailed"), Type: "validationFailed"},
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", MediaTypeProblemJSON)
+		eh.HandleError(w, r, c.Error)
+
+		var problem struct {
+			Type   string `json:"type"`
+			Status int    `json:"status"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+			t.Fatal(err)
+		}
+		if problem.Type != c.Type {
+			t.Fatalf("problem type %q does not match %q", problem.Type, c.Type)
+		}
+		if w.Code != problem.Status {
+			t.Fatal("status code mismatch:", w.Code, problem.Status)
+		}
+	}
+}
+
+func TestErrorTranslations(t *testing.T) {
+	bundle := i18n.NewBundle(language.AmericanEnglish)
+	if err := LoadTranslations(bundle); err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.LanguageTags()) < 4 {
+		t.Fatal("translations are missing:", bundle.LanguageTags())
+	}
+
+	for _, tag := range bundle.LanguageTags() {
+		lc := i18n.NewLocalizer(bundle, tag.String())
+		for _, standard := range NewStandardErrors() {
+			for _, message := range []*i18n.LocalizeConfig{standard.Title, standard.Description} {
+				_, localizedTag, err := lc.LocalizeWithTag(message)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if localizedTag != tag {
+					t.Errorf("message %q is not translated to %q", message.DefaultMessage.ID, tag)
+				}
+			}
+		}
+	}
+
+	eh := NewErrorHandler(bundle, nil, nil, NewStandardErrors()...)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", MediaTypeText)
+	r.Header.Set("Accept-Language", "es-MX")
+	eh.HandleError(w, r, NewGoneError(errors.New("test"), "removed"))
+	if w.Code != http.StatusGone {
+		t.Fatal("unexpected status code:", w.Code)
+	}
+	if !strings.HasPrefix(w.Body.String(), "Ya no disponible") {
+		t.Fatal("error was not translated:", w.Body.String())
+	}
+}
@@ -3,12 +3,27 @@ module github.com/dkotik/oakhttp
 go 1.23.3
 
 require (
-	github.com/coreos/go-systemd v0.0.0-20191104093116-d3cd4ed1dbcf // indirect
-	github.com/lmittmann/tint v1.0.7 // indirect
-	github.com/nicksnyder/go-i18n/v2 v2.6.0 // indirect
+	github.com/coreos/go-systemd v0.0.0-20191104093116-d3cd4ed1dbcf
+	github.com/lmittmann/tint v1.0.7
+	github.com/nicksnyder/go-i18n/v2 v2.6.0
+	github.com/quic-go/quic-go v0.52.0
+	github.com/relvacode/iso8601 v1.6.0
+	github.com/sebdah/goldie/v2 v2.5.5
+	golang.org/x/net v0.40.0
+	golang.org/x/text v0.25.0
+)
+
+require (
+	github.com/go-task/slim-sprig v0.0.0-20230315185526-52ccab3ef572 // indirect
+	github.com/google/pprof v0.0.0-20210407192527-94a9f03dee38 // indirect
+	github.com/onsi/ginkgo/v2 v2.9.5 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/relvacode/iso8601 v1.6.0 // indirect
-	github.com/sebdah/goldie/v2 v2.5.5 // indirect
+	github.com/quic-go/qpack v0.5.1 // indirect
 	github.com/sergi/go-diff v1.3.1 // indirect
-	golang.org/x/text v0.25.0 // indirect
+	go.uber.org/mock v0.5.0 // indirect
+	golang.org/x/crypto v0.38.0 // indirect
+	golang.org/x/mod v0.18.0 // indirect
+	golang.org/x/sync v0.14.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/tools v0.22.0 // indirect
 )
@@ -0,0 +1,76 @@
+package oakhttp
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// Handler is an improved [http.Handler] that reports failures by returning an error instead of writing them to the response directly.
+type Handler interface {
+	ServeHyperText(http.ResponseWriter, *http.Request) error
+}
+
+type HandlerFunc func(http.ResponseWriter, *http.Request) error
+
+func (f HandlerFunc) ServeHyperText(w http.ResponseWriter, r *http.Request) error {
+	return f(w, r)
+}
+
+type HandlerMiddleware func(Handler) Handler
+
+// ApplyHandlerMiddleware applies [HandlerMiddleware] in reverse to preserve logical order.
+func ApplyHandlerMiddleware(h Handler, mws []HandlerMiddleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+type handlerAdaptor struct {
+	Next         Handler
+	ErrorHandler ErrorHandler
+	Logger       *slog.Logger
+}
+
+// NewHandlerAdaptor turns a [Handler] into an [http.Handler]. Returned errors are passed to the [ErrorHandler] unless the response was already committed. Committed responses cannot be replaced by an error page, so those errors are only logged.
+func NewHandlerAdaptor(h Handler, eh ErrorHandler, logger *slog.Logger) http.Handler {
+	if h == nil {
+		panic("cannot use a <nil> handler")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if eh == nil {
+		eh = NewErrorHandler(nil, nil, logger)
+	}
+	return handlerAdaptor{
+		Next:         h,
+		ErrorHandler: eh,
+		Logger:       logger,
+	}
+}
+
+func (h handlerAdaptor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rw := newResponseWriter(w)
+	err := h.Next.ServeHyperText(rw, r)
+	if err == nil {
+		return
+	}
+	if rw.IsCommitted() {
+		h.Logger.Log(
+			r.Context(),
+			slog.LevelError,
+			"HTTP request failed after the response was committed",
+			slog.Any("error", err),
+			slog.Int("status_code", rw.StatusCode),
+			slog.Int64("bytes_written", rw.BytesWritten),
+			slog.Group("request",
+				slog.String("host", r.URL.Hostname()),
+				slog.String("path", r.URL.Path),
+				slog.String("method", r.Method),
+			),
+		)
+		return
+	}
+	h.ErrorHandler.HandleError(rw, r, err)
+}
@@ -0,0 +1,64 @@
+package oakhttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandlerAdaptor(t *testing.T) {
+	eh := NewErrorHandler(nil, nil, nil, NewStandardErrors()...)
+
+	t.Run("error", func(t *testing.T) {
+		h := NewHandlerAdaptor(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("X-Test", "set")
+			return NewNotFoundError(errors.New("test"), "")
+		}), eh, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusNotFound {
+			t.Fatal("unexpected status code:", w.Code)
+		}
+		if w.Body.Len() == 0 {
+			t.Fatal("error page was not rendered")
+		}
+	})
+
+	t.Run("committed", func(t *testing.T) {
+		h := NewHandlerAdaptor(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte("partial"))
+			return errors.New("test")
+		}), eh, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusAccepted {
+			t.Fatal("unexpected status code:", w.Code)
+		}
+		if body := w.Body.String(); body != "partial" {
+			t.Fatal("error page was appended to a committed response:", body)
+		}
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		order := ""
+		mw := func(name string) HandlerMiddleware {
+			return func(next Handler) Handler {
+				return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+					order += name
+					return next.ServeHyperText(w, r)
+				})
+			}
+		}
+		h := ApplyHandlerMiddleware(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return nil
+		}), []HandlerMiddleware{mw("a"), mw("b"), mw("c")})
+		if err := h.ServeHyperText(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+			t.Fatal(err)
+		}
+		if order != "abc" {
+			t.Fatal("middleware was applied out of order:", order)
+		}
+	})
+}
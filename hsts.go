@@ -0,0 +1,108 @@
+package oakhttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	StrictTransportSecurityHeader = "Strict-Transport-Security"
+
+	// DefaultStrictTransportSecurityMaxAge is one year, which is also the minimum accepted by the browser preload list.
+	DefaultStrictTransportSecurityMaxAge = time.Hour * 24 * 365
+)
+
+type strictTransportSecurityOptions struct {
+	MaxAge            time.Duration
+	MaxAgeIsSet       bool // zero max age is a valid setting
+	IncludeSubDomains bool
+	Preload           bool
+}
+
+type StrictTransportSecurityOption func(*strictTransportSecurityOptions) error
+
+// WithStrictTransportSecurityMaxAge sets how long browsers remember to only use HTTPS for the host. Zero max age asks browsers to forget the policy.
+func WithStrictTransportSecurityMaxAge(d time.Duration) StrictTransportSecurityOption {
+	return func(o *strictTransportSecurityOptions) error {
+		if o.MaxAgeIsSet {
+			return errors.New("strict transport security max age is already set")
+		}
+		if d < 0 {
+			return errors.New("strict transport security max age cannot be negative")
+		}
+		if d > time.Hour*24*365*2 {
+			return errors.New("cannot set strict transport security max age above two years")
+		}
+		o.MaxAge = d
+		o.MaxAgeIsSet = true
+		return nil
+	}
+}
+
+// WithStrictTransportSecurityIncludeSubDomains applies the policy to all subdomains of the host.
+func WithStrictTransportSecurityIncludeSubDomains() StrictTransportSecurityOption {
+	return func(o *strictTransportSecurityOptions) error {
+		if o.IncludeSubDomains {
+			return errors.New("strict transport security subdomains are already included")
+		}
+		o.IncludeSubDomains = true
+		return nil
+	}
+}
+
+// WithStrictTransportSecurityPreload signals consent to be included in the browser preload list. Requires subdomains to be included and max age of at least one year. Removal from the list takes months, see <https://hstspreload.org>.
+func WithStrictTransportSecurityPreload() StrictTransportSecurityOption {
+	return func(o *strictTransportSecurityOptions) error {
+		if o.Preload {
+			return errors.New("strict transport security preload is already set")
+		}
+		o.Preload = true
+		return nil
+	}
+}
+
+// NewStrictTransportSecurityMiddleware sets the Strict-Transport-Security header on every response. Browsers ignore the header when it arrives over plain HTTP, so it is safe to set behind a proxy that terminates TLS.
+func NewStrictTransportSecurityMiddleware(withOptions ...StrictTransportSecurityOption) (Middleware, error) {
+	o := &strictTransportSecurityOptions{}
+	for _, option := range append(
+		withOptions,
+		func(o *strictTransportSecurityOptions) error { // defaults and validation
+			if !o.MaxAgeIsSet {
+				o.MaxAge = DefaultStrictTransportSecurityMaxAge
+			}
+			if o.Preload {
+				if !o.IncludeSubDomains {
+					return errors.New("strict transport security preload requires subdomains to be included")
+				}
+				if o.MaxAge < DefaultStrictTransportSecurityMaxAge {
+					return errors.New("strict transport security preload requires max age of at least one year")
+				}
+			}
+			return nil
+		},
+	) {
+		if err := option(o); err != nil {
+			return nil, fmt.Errorf("cannot initialize strict transport security middleware: %w", err)
+		}
+	}
+
+	value := "max-age=" + strconv.FormatInt(int64(o.MaxAge/time.Second), 10)
+	if o.IncludeSubDomains {
+		value += "; includeSubDomains"
+	}
+	if o.Preload {
+		value += "; preload"
+	}
+	return func(next http.Handler) http.Handler {
+		if next == nil {
+			panic("cannot use a <nil> handler")
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(StrictTransportSecurityHeader, value)
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
@@ -0,0 +1,75 @@
+package oakhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStrictTransportSecurityMiddleware(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Options  []StrictTransportSecurityOption
+		Expected string
+	}{
+		{
+			Name:     "default",
+			Expected: "max-age=31536000",
+		},
+		{
+			Name: "subdomains",
+			Options: []StrictTransportSecurityOption{
+				WithStrictTransportSecurityMaxAge(time.Hour),
+				WithStrictTransportSecurityIncludeSubDomains(),
+			},
+			Expected: "max-age=3600; includeSubDomains",
+		},
+		{
+			Name: "preload",
+			Options: []StrictTransportSecurityOption{
+				WithStrictTransportSecurityIncludeSubDomains(),
+				WithStrictTransportSecurityPreload(),
+			},
+			Expected: "max-age=31536000; includeSubDomains; preload",
+		},
+		{
+			Name: "removal",
+			Options: []StrictTransportSecurityOption{
+				WithStrictTransportSecurityMaxAge(0),
+			},
+			Expected: "max-age=0",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			mw, err := NewStrictTransportSecurityMiddleware(c.Options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			mw(http.NotFoundHandler()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if header := w.Header().Get(StrictTransportSecurityHeader); header != c.Expected {
+				t.Fatalf("unexpected header value: %q", header)
+			}
+		})
+	}
+
+	if _, err := NewStrictTransportSecurityMiddleware(
+		WithStrictTransportSecurityMaxAge(0),
+		WithStrictTransportSecurityMaxAge(time.Hour),
+	); err == nil {
+		t.Fatal("max age should not be set twice")
+	}
+	if _, err := NewStrictTransportSecurityMiddleware(WithStrictTransportSecurityPreload()); err == nil {
+		t.Fatal("preload without subdomains should be rejected")
+	}
+	if _, err := NewStrictTransportSecurityMiddleware(
+		WithStrictTransportSecurityMaxAge(time.Hour),
+		WithStrictTransportSecurityIncludeSubDomains(),
+		WithStrictTransportSecurityPreload(),
+	); err == nil {
+		t.Fatal("preload with short max age should be rejected")
+	}
+}
@@ -46,3 +46,150 @@ var (
 		},
 	}
 )
+
+var (
+	ErrorValidationTitle = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorValidationTitle",
+			Other: http.StatusText(http.StatusUnprocessableEntity),
+		},
+	}
+	ErrorValidationDescription = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorValidationDescription",
+			Other: "Request contains invalid fields. Correct them and try again.",
+		},
+	}
+)
+
+var (
+	ErrorBadRequestTitle = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorBadRequestTitle",
+			Other: http.StatusText(http.StatusBadRequest),
+		},
+	}
+	ErrorBadRequestDescription = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorBadRequestDescription",
+			Other: "Request is malformed and cannot be processed.",
+		},
+	}
+
+	ErrorUnauthorizedTitle = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorUnauthorizedTitle",
+			Other: http.StatusText(http.StatusUnauthorized),
+		},
+	}
+	ErrorUnauthorizedDescription = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorUnauthorizedDescription",
+			Other: "Authentication is required to access this resource.",
+		},
+	}
+
+	ErrorMethodNotAllowedTitle = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorMethodNotAllowedTitle",
+			Other: http.StatusText(http.StatusMethodNotAllowed),
+		},
+	}
+	ErrorMethodNotAllowedDescription = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorMethodNotAllowedDescription",
+			Other: "Request method is not supported by this resource.",
+		},
+	}
+
+	ErrorConflictTitle = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorConflictTitle",
+			Other: http.StatusText(http.StatusConflict),
+		},
+	}
+	ErrorConflictDescription = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorConflictDescription",
+			Other: "Request conflicts with the current state of the resource.",
+		},
+	}
+
+	ErrorGoneTitle = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorGoneTitle",
+			Other: http.StatusText(http.StatusGone),
+		},
+	}
+	ErrorGoneDescription = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorGoneDescription",
+			Other: "Requested content is no longer available.",
+		},
+	}
+
+	ErrorRequestEntityTooLargeTitle = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorRequestEntityTooLargeTitle",
+			Other: http.StatusText(http.StatusRequestEntityTooLarge),
+		},
+	}
+	ErrorRequestEntityTooLargeDescription = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorRequestEntityTooLargeDescription",
+			Other: "Request content is larger than the service is willing to process.",
+		},
+	}
+
+	ErrorUnsupportedMediaTypeTitle = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorUnsupportedMediaTypeTitle",
+			Other: http.StatusText(http.StatusUnsupportedMediaType),
+		},
+	}
+	ErrorUnsupportedMediaTypeDescription = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorUnsupportedMediaTypeDescription",
+			Other: "Request content format is not supported.",
+		},
+	}
+
+	ErrorTooManyRequestsTitle = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorTooManyRequestsTitle",
+			Other: http.StatusText(http.StatusTooManyRequests),
+		},
+	}
+	ErrorTooManyRequestsDescription = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorTooManyRequestsDescription",
+			Other: "Too many requests were sent in a short period of time. Try again later.",
+		},
+	}
+
+	ErrorServiceUnavailableTitle = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorServiceUnavailableTitle",
+			Other: http.StatusText(http.StatusServiceUnavailable),
+		},
+	}
+	ErrorServiceUnavailableDescription = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorServiceUnavailableDescription",
+			Other: "Service is temporarily unable to handle the request. Try again later.",
+		},
+	}
+
+	ErrorGatewayTimeoutTitle = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorGatewayTimeoutTitle",
+			Other: http.StatusText(http.StatusGatewayTimeout),
+		},
+	}
+	ErrorGatewayTimeoutDescription = &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "ErrorGatewayTimeoutDescription",
+			Other: "Service did not complete the request in time.",
+		},
+	}
+)
@@ -0,0 +1,139 @@
+// Package tracecontext implements W3C Trace Context propagation shared by the server middleware and the client transport, so that the client does not depend on the server packages.
+package tracecontext
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// W3C Trace Context headers: https://www.w3.org/TR/trace-context/
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+
+	traceStateMaximumLength = 512
+)
+
+// TraceContext follows the W3C Trace Context specification. It continues a trace started by the caller and identifies the current request by its own span.
+type TraceContext struct {
+	TraceID      string
+	ParentSpanID string
+	SpanID       string
+	Flags        string
+	State        string
+}
+
+func (t *TraceContext) GetTraceID() string {
+	return t.TraceID
+}
+
+// IsSampled returns true if the caller may have recorded the trace.
+func (t *TraceContext) IsSampled() bool {
+	b, err := hex.DecodeString(t.Flags)
+	return err == nil && len(b) == 1 && b[0]&1 == 1
+}
+
+// TraceParent formats the traceparent header value that identifies the current span as the parent of any downstream requests.
+func (t *TraceContext) TraceParent() string {
+	return "00-" + t.TraceID + "-" + t.SpanID + "-" + t.Flags
+}
+
+// ParseTraceParent reads trace and parent span identifiers from a traceparent header value.
+func ParseTraceParent(value string) (traceID, parentSpanID, flags string, err error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return "", "", "", errors.New("traceparent must contain four fields")
+	}
+	version := parts[0]
+	if !isLowerHex(version, 2) || version == "ff" {
+		return "", "", "", fmt.Errorf("invalid traceparent version %q", version)
+	}
+	if version == "00" && len(parts) != 4 {
+		return "", "", "", errors.New("traceparent version 00 must contain exactly four fields")
+	}
+	traceID, parentSpanID, flags = parts[1], parts[2], parts[3]
+	if !isLowerHex(traceID, 32) || isZeroHex(traceID) {
+		return "", "", "", fmt.Errorf("invalid trace ID %q", traceID)
+	}
+	if !isLowerHex(parentSpanID, 16) || isZeroHex(parentSpanID) {
+		return "", "", "", fmt.Errorf("invalid parent span ID %q", parentSpanID)
+	}
+	if !isLowerHex(flags, 2) {
+		return "", "", "", fmt.Errorf("invalid trace flags %q", flags)
+	}
+	return traceID, parentSpanID, flags, nil
+}
+
+func isLowerHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isZeroHex(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+func newRandomHex(byteCount int) string {
+	b := make([]byte, byteCount)
+	for {
+		if _, err := rand.Read(b); err != nil {
+			panic(fmt.Errorf("crypto/rand is unavailable: %w", err))
+		}
+		for _, c := range b {
+			if c != 0 {
+				return hex.EncodeToString(b)
+			}
+		}
+	}
+}
+
+// New continues the trace described by traceparent and tracestate header values with a new span. A new trace is started if the traceparent value is empty or invalid.
+func New(traceParent, traceState string) *TraceContext {
+	traceID, parentSpanID, flags, err := ParseTraceParent(traceParent)
+	if err != nil {
+		return &TraceContext{
+			TraceID: newRandomHex(16),
+			SpanID:  newRandomHex(8),
+			Flags:   "01",
+		}
+	}
+	if len(traceState) > traceStateMaximumLength {
+		traceState = ""
+	}
+	return &TraceContext{
+		TraceID:      traceID,
+		ParentSpanID: parentSpanID,
+		SpanID:       newRandomHex(8),
+		Flags:        flags,
+		State:        strings.TrimSpace(traceState),
+	}
+}
+
+type contextKey struct{}
+
+// ContextWith stores a trace in the context. The trace is any value that reports a trace ID, such as a [TraceContext].
+func ContextWith(parent context.Context, trace any) context.Context {
+	return context.WithValue(parent, contextKey{}, trace)
+}
+
+// Value returns the trace stored by [ContextWith].
+func Value(ctx context.Context) any {
+	return ctx.Value(contextKey{})
+}
+
+// FromContext recovers the [TraceContext] stored by [ContextWith].
+func FromContext(ctx context.Context) (*TraceContext, bool) {
+	t, ok := Value(ctx).(*TraceContext)
+	return t, ok
+}
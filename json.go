@@ -0,0 +1,103 @@
+package oakhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxJSONBodySize limits JSON request bodies when no other limit is given.
+const DefaultMaxJSONBodySize = 1 << 20
+
+// Validator is implemented by request payloads that can check their own consistency. [DecodeValidJSON] calls it after decoding.
+type Validator interface {
+	Validate() error
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == MediaTypeJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+// DecodeJSON reads the request body into a value of type T. The body must be declared as JSON by the Content-Type header, must not exceed the size limit, and must not contain unknown fields or trailing data. Failures are reported as [Error] values with status codes 415, 413, or 400. The limit defaults to [DefaultMaxJSONBodySize] when it is less than 1.
+func DecodeJSON[T any](w http.ResponseWriter, r *http.Request, limit int64) (value T, err error) {
+	if !isJSONContentType(r.Header.Get("Content-Type")) {
+		return value, NewUnsupportedMediaTypeError(
+			fmt.Errorf("expected JSON content type, got %q", r.Header.Get("Content-Type")),
+			"unsupportedContentType",
+		)
+	}
+	if limit < 1 {
+		limit = DefaultMaxJSONBodySize
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&value); err != nil {
+		return value, newJSONDecodingError(err)
+	}
+	if err = decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = errors.New("request body contains more than one JSON value")
+		}
+		return value, newJSONDecodingError(err)
+	}
+	return value, nil
+}
+
+// DecodeValidJSON is [DecodeJSON] that also calls [Validator.Validate] if the decoded type implements it. Validation errors that do not carry their own status code are reported as [http.StatusUnprocessableEntity].
+func DecodeValidJSON[T any](w http.ResponseWriter, r *http.Request, limit int64) (value T, err error) {
+	value, err = DecodeJSON[T](w, r, limit)
+	if err != nil {
+		return value, err
+	}
+
+	validator, ok := any(&value).(Validator)
+	if !ok {
+		if validator, ok = any(value).(Validator); !ok {
+			return value, nil
+		}
+	}
+	if err = validator.Validate(); err != nil {
+		var withStatusCode ErrorWithStatusCode
+		if errors.As(err, &withStatusCode) {
+			return value, err
+		}
+		return value, NewUnprocessableEntityError(err, "validationFailed")
+	}
+	return value, nil
+}
+
+func newJSONDecodingError(err error) error {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return NewRequestEntityTooLargeError(
+			fmt.Errorf("request body exceeds %d bytes", maxBytesError.Limit),
+			"requestBodyTooLarge",
+		)
+	}
+	if errors.Is(err, io.EOF) {
+		return NewBadRequestError(errors.New("request body is empty"), "malformedJSON")
+	}
+	return NewBadRequestError(fmt.Errorf("cannot decode JSON request body: %w", err), "malformedJSON")
+}
+
+// EncodeJSON writes the value as a JSON response with the given status code. The value is encoded before any headers are written, so encoding failures can still be reported by an [ErrorHandler].
+func EncodeJSON[T any](w http.ResponseWriter, statusCode int, value T) error {
+	b := &bytes.Buffer{}
+	if err := json.NewEncoder(b).Encode(value); err != nil {
+		return fmt.Errorf("cannot encode JSON response: %w", err)
+	}
+	w.Header().Set("Content-Type", MediaTypeJSON)
+	w.WriteHeader(statusCode)
+	_, err := io.Copy(w, b)
+	return err
+}
@@ -0,0 +1,87 @@
+package oakhttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testJSONPayload struct {
+	Name string `json:"name"`
+}
+
+func (p testJSONPayload) Validate() error {
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestDecodeJSON(t *testing.T) {
+	cases := []struct {
+		Name          string
+		ContentType   string
+		Body          string
+		StatusCode    int
+		KnowledgeCode string
+	}{
+		{Name: "valid", ContentType: "application/json; charset=utf-8", Body: `{"name":"oak"}`},
+		{Name: "structured suffix", ContentType: "application/merge-patch+json", Body: `{"name":"oak"}`},
+		{Name: "wrong content type", ContentType: "text/plain", Body: `{"name":"oak"}`, StatusCode: http.StatusUnsupportedMediaType},
+		{Name: "too large", ContentType: MediaTypeJSON, Body: `{"name":"` + strings.Repeat("o", 64) + `"}`, StatusCode: http.StatusRequestEntityTooLarge},
+		{Name: "unknown field", ContentType: MediaTypeJSON, Body: `{"name":"oak","age":3}`, StatusCode: http.StatusBadRequest, KnowledgeCode: "malformedJSON"},
+		{Name: "trailing data", ContentType: MediaTypeJSON, Body: `{"name":"oak"}{}`, StatusCode: http.StatusBadRequest},
+		{Name: "empty", ContentType: MediaTypeJSON, Body: ``, StatusCode: http.StatusBadRequest},
+		{Name: "invalid", ContentType: MediaTypeJSON, Body: `{"name":""}`, StatusCode: http.StatusUnprocessableEntity, KnowledgeCode: "validationFailed"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(c.Body))
+			r.Header.Set("Content-Type", c.ContentType)
+			payload, err := DecodeValidJSON[testJSONPayload](httptest.NewRecorder(), r, 32)
+			if c.StatusCode == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if payload.Name != "oak" {
+					t.Fatal("unexpected payload:", payload)
+				}
+				return
+			}
+
+			var httpError Error
+			if !errors.As(err, &httpError) {
+				t.Fatal("expected an HTTP error, got:", err)
+			}
+			if httpError.StatusCode != c.StatusCode {
+				t.Fatalf("status code %d does not match %d: %v", httpError.StatusCode, c.StatusCode, err)
+			}
+			if c.KnowledgeCode != "" && httpError.KnowledgeCode != c.KnowledgeCode {
+				t.Fatalf("knowledge code %q does not match %q", httpError.KnowledgeCode, c.KnowledgeCode)
+			}
+		})
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := EncodeJSON(w, http.StatusCreated, testJSONPayload{Name: "oak"}); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusCreated {
+		t.Fatal("unexpected status code:", w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != MediaTypeJSON {
+		t.Fatal("unexpected content type:", contentType)
+	}
+	if body := strings.TrimSpace(w.Body.String()); body != `{"name":"oak"}` {
+		t.Fatal("unexpected body:", body)
+	}
+
+	if err := EncodeJSON(httptest.NewRecorder(), http.StatusOK, func() {}); err == nil {
+		t.Fatal("encoding a function did not fail")
+	}
+}
@@ -0,0 +1,132 @@
+package oakhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// timeoutResponseGrace extends the write deadline beyond the route timeout to leave room for writing the timeout error response.
+const timeoutResponseGrace = time.Second
+
+var errRouteTimeout = errors.New("route timeout")
+
+type timeoutOptions struct {
+	GatewayTimeout bool
+}
+
+type TimeoutOption func(*timeoutOptions) error
+
+// WithGatewayTimeout responds with [http.StatusGatewayTimeout] instead of [http.StatusServiceUnavailable]. Use it for routes that mostly wait on upstream services.
+func WithGatewayTimeout() TimeoutOption {
+	return func(o *timeoutOptions) error {
+		if o.GatewayTimeout {
+			return errors.New("gateway timeout is already set")
+		}
+		o.GatewayTimeout = true
+		return nil
+	}
+}
+
+// NewTimeoutMiddleware cancels the request context after the timeout and moves the connection write deadline using [http.ResponseController], so that a slow route can outlast the server write timeout while other routes stay strict. If the deadline passes before the handler commits a response, the error handler renders [http.StatusServiceUnavailable] or [http.StatusGatewayTimeout] with [WithGatewayTimeout]. Handlers must observe context cancellation: unlike [http.TimeoutHandler], the response is not buffered, so streaming keeps working. The connection is closed if the handler keeps writing past the deadline.
+func NewTimeoutMiddleware(eh ErrorHandler, timeout time.Duration, withOptions ...TimeoutOption) Middleware {
+	o := &timeoutOptions{}
+	for _, option := range append(
+		withOptions,
+		func(o *timeoutOptions) error { // validate
+			if timeout <= 0 {
+				return errors.New("timeout must be positive")
+			}
+			return nil
+		},
+	) {
+		if err := option(o); err != nil {
+			panic(fmt.Errorf("cannot initialize timeout middleware: %w", err))
+		}
+	}
+	if eh == nil {
+		eh = NewErrorHandler(nil, nil, nil)
+	}
+
+	var timeoutError Error
+	if o.GatewayTimeout {
+		timeoutError = NewGatewayTimeoutError(fmt.Errorf("request exceeded %s deadline", timeout), "routeTimedOut")
+	} else {
+		timeoutError = NewServiceUnavailableError(fmt.Errorf("request exceeded %s deadline", timeout), "routeTimedOut")
+	}
+
+	return func(next http.Handler) http.Handler {
+		if next == nil {
+			panic("next HTTP handler is nil")
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := newResponseWriter(w)
+			// ignore [http.ErrNotSupported], the context deadline still applies
+			_ = http.NewResponseController(rw).SetWriteDeadline(time.Now().Add(timeout + timeoutResponseGrace))
+
+			ctx, cancel := context.WithTimeoutCause(r.Context(), timeout, errRouteTimeout)
+			defer cancel()
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			if !rw.IsCommitted() && errors.Is(context.Cause(ctx), errRouteTimeout) {
+				eh.HandleError(rw, r, timeoutError)
+			}
+		})
+	}
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	Exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		b.Exceeded = true
+	}
+	return n, err
+}
+
+// NewMaxBodySizeMiddleware caps the request body at limit bytes. Requests that declare a larger Content-Length are rejected before reaching the handler. Requests that turn out to be larger while the handler reads them fail with [http.MaxBytesError]. In both cases, unless the handler already committed a response, the error handler renders [http.StatusRequestEntityTooLarge].
+func NewMaxBodySizeMiddleware(eh ErrorHandler, limit int64) Middleware {
+	if limit < 1 {
+		panic("cannot initialize max body size middleware: limit must be positive")
+	}
+	if eh == nil {
+		eh = NewErrorHandler(nil, nil, nil)
+	}
+	tooLarge := NewRequestEntityTooLargeError(
+		fmt.Errorf("request body exceeds %d bytes", limit),
+		"requestBodyTooLarge",
+	)
+
+	return func(next http.Handler) http.Handler {
+		if next == nil {
+			panic("next HTTP handler is nil")
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				w.Header().Set("Connection", "close") // do not read the body
+				eh.HandleError(w, r, tooLarge)
+				return
+			}
+			if r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rw := newResponseWriter(w)
+			body := &limitedBody{ReadCloser: http.MaxBytesReader(rw, r.Body, limit)}
+			r.Body = body
+			next.ServeHTTP(rw, r)
+			if body.Exceeded && !rw.IsCommitted() {
+				eh.HandleError(rw, r, tooLarge)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package oakhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	eh := NewErrorHandler(nil, nil, nil)
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Millisecond * 100):
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	cases := []struct {
+		Name       string
+		Handler    http.Handler
+		StatusCode int
+	}{
+		{"service unavailable", NewTimeoutMiddleware(eh, time.Millisecond*10)(slow), http.StatusServiceUnavailable},
+		{"gateway timeout", NewTimeoutMiddleware(eh, time.Millisecond*10, WithGatewayTimeout())(slow), http.StatusGatewayTimeout},
+		{"in time", NewTimeoutMiddleware(eh, time.Second)(slow), http.StatusNoContent},
+		{"committed", NewTimeoutMiddleware(eh, time.Millisecond*10)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			<-r.Context().Done()
+		})), http.StatusAccepted},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", MediaTypeProblemJSON)
+			c.Handler.ServeHTTP(w, r)
+			if w.Code != c.StatusCode {
+				t.Fatal("unexpected status code:", w.Code)
+			}
+			if c.StatusCode >= 500 && !strings.Contains(w.Body.String(), http.StatusText(c.StatusCode)) {
+				t.Fatal("error was not rendered by the error handler:", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMaxBodySizeMiddleware(t *testing.T) {
+	mw := NewMaxBodySizeMiddleware(NewErrorHandler(nil, nil, nil), 8)
+	read := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			return // leave the response to the middleware
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	t.Run("declared length", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		read.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("0123456789")))
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Fatal("unexpected status code:", w.Code)
+		}
+	})
+
+	t.Run("streamed", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader("0123456789")))
+		r.ContentLength = -1
+		w := httptest.NewRecorder()
+		read.ServeHTTP(w, r)
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Fatal("unexpected status code:", w.Code)
+		}
+	})
+
+	t.Run("within limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		read.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("01234567")))
+		if w.Code != http.StatusNoContent {
+			t.Fatal("unexpected status code:", w.Code)
+		}
+	})
+
+	t.Run("decode JSON", func(t *testing.T) {
+		h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := DecodeJSON[map[string]any](w, r, DefaultMaxJSONBodySize); err != nil {
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		r := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader(`{"key":"value"}`)))
+		r.Header.Set("Content-Type", MediaTypeJSON)
+		r.ContentLength = -1
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Fatal("unexpected status code:", w.Code)
+		}
+	})
+}
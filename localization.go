@@ -0,0 +1,197 @@
+package oakhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+)
+
+type localizationContextKey struct{}
+
+type requestLocalization struct {
+	Localizer *i18n.Localizer
+	Language  language.Tag
+}
+
+// ContextWithLocalizer stores the resolved request language and its localizer.
+func ContextWithLocalizer(parent context.Context, lc *i18n.Localizer, tag language.Tag) context.Context {
+	return context.WithValue(parent, localizationContextKey{}, requestLocalization{
+		Localizer: lc,
+		Language:  tag,
+	})
+}
+
+// LocalizerFromContext returns the localizer resolved by [NewLocalizationMiddleware]. Falls back to the default language of [LocalizationBundle].
+func LocalizerFromContext(ctx context.Context) *i18n.Localizer {
+	if l, ok := ctx.Value(localizationContextKey{}).(requestLocalization); ok && l.Localizer != nil {
+		return l.Localizer
+	}
+	return i18n.NewLocalizer(LocalizationBundle)
+}
+
+// LanguageFromContext returns the language resolved by [NewLocalizationMiddleware].
+func LanguageFromContext(ctx context.Context) (language.Tag, bool) {
+	l, ok := ctx.Value(localizationContextKey{}).(requestLocalization)
+	if !ok {
+		return language.Und, false
+	}
+	return l.Language, true
+}
+
+type localizationOptions struct {
+	Bundle         *i18n.Bundle
+	QueryParameter string
+	CookieName     string
+	MatchOptions   []language.MatchOption
+}
+
+type LocalizationOption func(*localizationOptions) error
+
+func WithLocalizationBundle(b *i18n.Bundle) LocalizationOption {
+	return func(o *localizationOptions) error {
+		if o.Bundle != nil {
+			return errors.New("localization bundle is already set")
+		}
+		if b == nil {
+			return errors.New("cannot use a <nil> localization bundle")
+		}
+		o.Bundle = b
+		return nil
+	}
+}
+
+// WithLanguageQueryParameter lets the named URL query parameter override the Accept-Language header.
+func WithLanguageQueryParameter(name string) LocalizationOption {
+	return func(o *localizationOptions) error {
+		if o.QueryParameter != "" {
+			return errors.New("language query parameter is already set")
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return errors.New("cannot use an empty language query parameter")
+		}
+		o.QueryParameter = name
+		return nil
+	}
+}
+
+// WithLanguageCookie lets the named cookie override the Accept-Language header. The query parameter takes precedence over the cookie.
+func WithLanguageCookie(name string) LocalizationOption {
+	return func(o *localizationOptions) error {
+		if o.CookieName != "" {
+			return errors.New("language cookie is already set")
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return errors.New("cannot use an empty language cookie name")
+		}
+		o.CookieName = name
+		return nil
+	}
+}
+
+func WithLanguageMatchOptions(options ...language.MatchOption) LocalizationOption {
+	return func(o *localizationOptions) error {
+		if o.MatchOptions != nil {
+			return errors.New("language match options are already set")
+		}
+		if len(options) == 0 {
+			return errors.New("provide at least one language match option")
+		}
+		o.MatchOptions = options
+		return nil
+	}
+}
+
+type localizationHandler struct {
+	Next           http.Handler
+	Bundle         *i18n.Bundle
+	Matcher        language.Matcher
+	Languages      []language.Tag
+	QueryParameter string
+	CookieName     string
+}
+
+// NewLocalizationMiddleware resolves the request language once and stores it in the request context together with its localizer. The language is matched against the bundle's language tags, so the bundle must be fully loaded before the middleware is created. [ErrorHandler]s created by [NewErrorHandler] respect the resolved language.
+func NewLocalizationMiddleware(withOptions ...LocalizationOption) (Middleware, error) {
+	o := &localizationOptions{}
+	var err error
+	for _, option := range append(
+		withOptions,
+		func(o *localizationOptions) error { // defaults
+			if o.Bundle == nil {
+				o.Bundle = LocalizationBundle
+			}
+			if o.MatchOptions == nil {
+				o.MatchOptions = []language.MatchOption{language.PreferSameScript(true)}
+			}
+			if len(o.Bundle.LanguageTags()) == 0 {
+				return errors.New("localization bundle contains zero translation languages")
+			}
+			return nil
+		},
+	) {
+		if err = option(o); err != nil {
+			return nil, fmt.Errorf("cannot initialize localization middleware: %w", err)
+		}
+	}
+
+	languages := o.Bundle.LanguageTags()
+	matcher := language.NewMatcher(languages, o.MatchOptions...)
+	return func(next http.Handler) http.Handler {
+		if next == nil {
+			panic("cannot use a <nil> handler")
+		}
+		return localizationHandler{
+			Next:           next,
+			Bundle:         o.Bundle,
+			Matcher:        matcher,
+			Languages:      languages,
+			QueryParameter: o.QueryParameter,
+			CookieName:     o.CookieName,
+		}
+	}, nil
+}
+
+func (h localizationHandler) preferredLanguages(r *http.Request) (preferred []language.Tag) {
+	if h.QueryParameter != "" {
+		if tag, err := language.Parse(r.URL.Query().Get(h.QueryParameter)); err == nil {
+			preferred = append(preferred, tag)
+		}
+	}
+	if h.CookieName != "" {
+		if c, err := r.Cookie(h.CookieName); err == nil {
+			if tag, err := language.Parse(c.Value); err == nil {
+				preferred = append(preferred, tag)
+			}
+		}
+	}
+	accepted, _, _ := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	return append(preferred, accepted...)
+}
+
+func (h localizationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// matcher returns a tag with extensions, index is reliable
+	_, index, _ := h.Matcher.Match(h.preferredLanguages(r)...)
+	tag := h.Languages[index]
+	w.Header().Add("Vary", "Accept-Language")
+	h.Next.ServeHTTP(w, r.WithContext(ContextWithLocalizer(
+		r.Context(),
+		i18n.NewLocalizer(h.Bundle, tag.String()),
+		tag,
+	)))
+}
+
+// requestLanguages returns the language resolved by [NewLocalizationMiddleware] or falls back to the Accept-Language header.
+func requestLanguages(r *http.Request) []language.Tag {
+	if tag, ok := LanguageFromContext(r.Context()); ok {
+		return []language.Tag{tag}
+	}
+	preferred, _, _ := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	return preferred
+}
@@ -0,0 +1,69 @@
+package oakhttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+)
+
+func TestLocalizationMiddleware(t *testing.T) {
+	bundle := i18n.NewBundle(language.AmericanEnglish)
+	if err := LoadTranslations(bundle); err != nil {
+		t.Fatal(err)
+	}
+	mw, err := NewLocalizationMiddleware(
+		WithLocalizationBundle(bundle),
+		WithLanguageQueryParameter("lang"),
+		WithLanguageCookie("lang"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eh := NewErrorHandler(bundle, nil, nil, NewStandardErrors()...)
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tag, ok := LanguageFromContext(r.Context())
+		if !ok {
+			t.Fatal("language is not set")
+		}
+		w.Header().Set("Content-Language", tag.String())
+		eh.HandleError(w, r, NewNotFoundError(errors.New("test"), ""))
+	}))
+
+	cases := []struct {
+		Name           string
+		URL            string
+		Cookie         string
+		AcceptLanguage string
+		Language       string
+		Title          string
+	}{
+		{Name: "default", URL: "/", Language: "en-US", Title: "Not Found"},
+		{Name: "header", URL: "/", AcceptLanguage: "de-CH,de;q=0.9", Language: "de", Title: "Nicht gefunden"},
+		{Name: "cookie", URL: "/", Cookie: "ru", AcceptLanguage: "de", Language: "ru", Title: "Не найдено"},
+		{Name: "query", URL: "/?lang=fr", Cookie: "ru", AcceptLanguage: "de", Language: "fr", Title: "Introuvable"},
+		{Name: "unknown", URL: "/?lang=xx", AcceptLanguage: "ja", Language: "en-US", Title: "Not Found"},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, c.URL, nil)
+			r.Header.Set("Accept", MediaTypeText)
+			r.Header.Set("Accept-Language", c.AcceptLanguage)
+			if c.Cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "lang", Value: c.Cookie})
+			}
+			h.ServeHTTP(w, r)
+			if language := w.Header().Get("Content-Language"); language != c.Language {
+				t.Fatalf("language %q does not match %q", language, c.Language)
+			}
+			if !strings.HasPrefix(w.Body.String(), c.Title) {
+				t.Fatalf("error page is not in the request language: %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBuckets are histogram upper bounds in seconds suitable for request durations.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) Add(delta float64) {
+	for {
+		current := f.bits.Load()
+		if f.bits.CompareAndSwap(current, math.Float64bits(math.Float64frombits(current)+delta)) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) Set(value float64) {
+	f.bits.Store(math.Float64bits(value))
+}
+
+func (f *atomicFloat) Load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+const labelValueSeparator = "\xff"
+
+// vector keeps one series per unique combination of label values.
+type vector[T any] struct {
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+	create     func() *T
+
+	mu     sync.RWMutex
+	series map[string]*T
+	values map[string][]string
+}
+
+func newVector[T any](kind, name, help string, labelNames []string, create func() *T) *vector[T] {
+	return &vector[T]{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: slices.Clone(labelNames),
+		create:     create,
+		series:     make(map[string]*T),
+		values:     make(map[string][]string),
+	}
+}
+
+func (v *vector[T]) Describe() (name, help, kind string, labelNames []string) {
+	return v.name, v.help, v.kind, v.labelNames
+}
+
+func (v *vector[T]) with(labelValues []string) *T {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("metric %q expects %d label values, got %d", v.name, len(v.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, labelValueSeparator)
+	v.mu.RLock()
+	s, ok := v.series[key]
+	v.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if s, ok = v.series[key]; ok {
+		return s
+	}
+	s = v.create()
+	v.series[key] = s
+	v.values[key] = slices.Clone(labelValues)
+	return s
+}
+
+func (v *vector[T]) each(f func(labelValues []string, s *T)) {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	series := make([]*T, len(keys))
+	values := make([][]string, len(keys))
+	for i, key := range keys {
+		series[i] = v.series[key]
+		values[i] = v.values[key]
+	}
+	v.mu.RUnlock()
+
+	for i := range series {
+		f(values[i], series[i])
+	}
+}
+
+// Counter is a monotonically increasing value partitioned by labels.
+type Counter struct {
+	vector *vector[atomicFloat]
+}
+
+// NewCounter registers a counter. Registering the same counter twice returns the existing one.
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	return r.register(&Counter{
+		vector: newVector("counter", name, help, labelNames, func() *atomicFloat {
+			return &atomicFloat{}
+		}),
+	}).(*Counter)
+}
+
+func (c *Counter) Describe() (name, help, kind string, labelNames []string) {
+	return c.vector.Describe()
+}
+
+func (c *Counter) Collect() (samples []sample) {
+	c.vector.each(func(labelValues []string, value *atomicFloat) {
+		samples = append(samples, sample{
+			LabelNames:  c.vector.labelNames,
+			LabelValues: labelValues,
+			Value:       value.Load(),
+		})
+	})
+	return samples
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.vector.with(labelValues).Add(1)
+}
+
+// Add increases the counter. Panics if delta is negative.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("counter %q cannot decrease", c.vector.name))
+	}
+	c.vector.with(labelValues).Add(delta)
+}
+
+// Gauge is a value that can go up and down partitioned by labels.
+type Gauge struct {
+	vector *vector[atomicFloat]
+}
+
+// NewGauge registers a gauge. Registering the same gauge twice returns the existing one.
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	return r.register(&Gauge{
+		vector: newVector("gauge", name, help, labelNames, func() *atomicFloat {
+			return &atomicFloat{}
+		}),
+	}).(*Gauge)
+}
+
+func (g *Gauge) Describe() (name, help, kind string, labelNames []string) {
+	return g.vector.Describe()
+}
+
+func (g *Gauge) Collect() (samples []sample) {
+	g.vector.each(func(labelValues []string, value *atomicFloat) {
+		samples = append(samples, sample{
+			LabelNames:  g.vector.labelNames,
+			LabelValues: labelValues,
+			Value:       value.Load(),
+		})
+	})
+	return samples
+}
+
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.vector.with(labelValues).Set(value)
+}
+
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.vector.with(labelValues).Add(delta)
+}
+
+func (g *Gauge) Inc(labelValues ...string) {
+	g.vector.with(labelValues).Add(1)
+}
+
+func (g *Gauge) Dec(labelValues ...string) {
+	g.vector.with(labelValues).Add(-1)
+}
+
+// GaugeFunc is a gauge whose values are read from functions at collection time. It is useful for reporting sizes of structures that already keep track of them.
+type GaugeFunc struct {
+	vector *vector[atomic.Pointer[func() float64]]
+}
+
+// NewGaugeFunc registers a function gauge. Registering the same gauge twice returns the existing one.
+func (r *Registry) NewGaugeFunc(name, help string, labelNames ...string) *GaugeFunc {
+	return r.register(&GaugeFunc{
+		vector: newVector("gauge", name, help, labelNames, func() *atomic.Pointer[func() float64] {
+			return &atomic.Pointer[func() float64]{}
+		}),
+	}).(*GaugeFunc)
+}
+
+func (g *GaugeFunc) Describe() (name, help, kind string, labelNames []string) {
+	return g.vector.Describe()
+}
+
+func (g *GaugeFunc) Collect() (samples []sample) {
+	g.vector.each(func(labelValues []string, f *atomic.Pointer[func() float64]) {
+		if load := f.Load(); load != nil {
+			samples = append(samples, sample{
+				LabelNames:  g.vector.labelNames,
+				LabelValues: labelValues,
+				Value:       (*load)(),
+			})
+		}
+	})
+	return samples
+}
+
+// Track reads the series value from the function on every collection. Replaces any function previously tracked with the same label values.
+func (g *GaugeFunc) Track(f func() float64, labelValues ...string) {
+	if f == nil {
+		panic(fmt.Sprintf("gauge %q cannot track a <nil> function", g.vector.name))
+	}
+	g.vector.with(labelValues).Store(&f)
+}
+
+type histogramSeries struct {
+	buckets []atomic.Uint64
+	count   atomic.Uint64
+	sum     atomicFloat
+}
+
+// Histogram counts observations into cumulative buckets partitioned by labels.
+type Histogram struct {
+	buckets []float64
+	vector  *vector[histogramSeries]
+}
+
+// NewHistogram registers a histogram with sorted upper bounds. Uses [DefaultBuckets] when none are given. Registering the same histogram twice returns the existing one.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Clone(buckets)
+	if !slices.IsSorted(buckets) {
+		panic(fmt.Sprintf("histogram %q buckets must be sorted", name))
+	}
+	if math.IsInf(buckets[len(buckets)-1], 1) {
+		buckets = buckets[:len(buckets)-1] // +Inf bucket is implicit
+	}
+	return r.register(&Histogram{
+		buckets: buckets,
+		vector: newVector("histogram", name, help, labelNames, func() *histogramSeries {
+			return &histogramSeries{
+				buckets: make([]atomic.Uint64, len(buckets)),
+			}
+		}),
+	}).(*Histogram)
+}
+
+func (h *Histogram) Describe() (name, help, kind string, labelNames []string) {
+	return h.vector.Describe()
+}
+
+func (h *Histogram) Collect() (samples []sample) {
+	labelNames := append(slices.Clone(h.vector.labelNames), "le")
+	h.vector.each(func(labelValues []string, s *histogramSeries) {
+		for i, upperBound := range h.buckets {
+			samples = append(samples, sample{
+				Suffix:      "_bucket",
+				LabelNames:  labelNames,
+				LabelValues: append(slices.Clone(labelValues), formatFloat(upperBound)),
+				Value:       float64(s.buckets[i].Load()),
+			})
+		}
+		count := float64(s.count.Load())
+		samples = append(samples,
+			sample{
+				Suffix:      "_bucket",
+				LabelNames:  labelNames,
+				LabelValues: append(slices.Clone(labelValues), "+Inf"),
+				Value:       count,
+			},
+			sample{
+				Suffix:      "_sum",
+				LabelNames:  h.vector.labelNames,
+				LabelValues: labelValues,
+				Value:       s.sum.Load(),
+			},
+			sample{
+				Suffix:      "_count",
+				LabelNames:  h.vector.labelNames,
+				LabelValues: labelValues,
+				Value:       count,
+			},
+		)
+	})
+	return samples
+}
+
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	s := h.vector.with(labelValues)
+	for i, upperBound := range h.buckets {
+		if value <= upperBound {
+			s.buckets[i].Add(1)
+		}
+	}
+	s.sum.Add(value)
+	s.count.Add(1)
+}
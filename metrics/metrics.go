@@ -0,0 +1,175 @@
+/*
+Package metrics collects counters, gauges, and histograms and exposes them in the Prometheus text format without external dependencies.
+
+	registry := metrics.NewRegistry()
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", registry)
+	mux.Handle("GET /users/{id}", usersHandler)
+
+	err := server.Run(
+	  context.Background(),
+	  server.WithHandler(metrics.NewMiddleware(registry)(mux)),
+	  server.WithMetrics(registry),
+	)
+
+Exposition format: <https://prometheus.io/docs/instrumenting/exposition_formats/>
+*/
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	nameExpression  = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelExpression = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+type sample struct {
+	Suffix      string
+	LabelNames  []string
+	LabelValues []string
+	Value       float64
+}
+
+type family interface {
+	Describe() (name, help, kind string, labelNames []string)
+	Collect() []sample
+}
+
+// Registry holds metric families and serves them as an [http.Handler].
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		families: make(map[string]family),
+	}
+}
+
+// register adds the family or returns the one already registered under the same name, kind, and labels. Panics on conflicting registrations.
+func (r *Registry) register(f family) family {
+	name, help, kind, labelNames := f.Describe()
+	if !nameExpression.MatchString(name) {
+		panic(fmt.Sprintf("invalid metric name %q", name))
+	}
+	if strings.TrimSpace(help) == "" {
+		panic(fmt.Sprintf("metric %q requires a help string", name))
+	}
+	for _, labelName := range labelNames {
+		if !labelExpression.MatchString(labelName) || strings.HasPrefix(labelName, "__") {
+			panic(fmt.Sprintf("metric %q has invalid label name %q", name, labelName))
+		}
+		if labelName == "le" && kind == "histogram" {
+			panic(fmt.Sprintf("histogram %q cannot use reserved label name %q", name, labelName))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.families[name]
+	if !ok {
+		r.families[name] = f
+		return f
+	}
+	_, existingHelp, existingKind, existingLabelNames := existing.Describe()
+	if existingKind != kind || existingHelp != help || !slices.Equal(existingLabelNames, labelNames) {
+		panic(fmt.Sprintf("metric %q is already registered with a different description", name))
+	}
+	return existing
+}
+
+// ServeHTTP writes all metrics in the Prometheus text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	b := bufio.NewWriter(w)
+	r.Write(b)
+	_ = b.Flush()
+}
+
+// Write renders all metrics in the Prometheus text exposition format sorted by name.
+func (r *Registry) Write(b *bufio.Writer) {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	families := make([]family, 0, len(names))
+	slices.Sort(names)
+	for _, name := range names {
+		families = append(families, r.families[name])
+	}
+	r.mu.Unlock()
+
+	for _, f := range families {
+		name, help, kind, _ := f.Describe()
+		b.WriteString("# HELP ")
+		b.WriteString(name)
+		b.WriteByte(' ')
+		b.WriteString(escapeHelp(help))
+		b.WriteString("\n# TYPE ")
+		b.WriteString(name)
+		b.WriteByte(' ')
+		b.WriteString(kind)
+		b.WriteByte('\n')
+		for _, s := range f.Collect() {
+			b.WriteString(name)
+			b.WriteString(s.Suffix)
+			if len(s.LabelNames) > 0 {
+				b.WriteByte('{')
+				for i, labelName := range s.LabelNames {
+					if i > 0 {
+						b.WriteByte(',')
+					}
+					b.WriteString(labelName)
+					b.WriteString(`="`)
+					b.WriteString(escapeLabelValue(s.LabelValues[i]))
+					b.WriteByte('"')
+				}
+				b.WriteByte('}')
+			}
+			b.WriteByte(' ')
+			b.WriteString(formatFloat(s.Value))
+			b.WriteByte('\n')
+		}
+	}
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dkotik/oakhttp/store"
+)
+
+func scrape(t *testing.T, r *Registry) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if contentType := w.Header().Get("Content-Type"); contentType != ContentType {
+		t.Fatalf("unexpected content type: %q", contentType)
+	}
+	b, err := io.ReadAll(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestExposition(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("test_events_total", "Events with \\ and\nnewline.", "kind")
+	c.Inc(`quote"d`)
+	c.Add(2, "plain")
+	g := r.NewGauge("test_temperature", "Current temperature.")
+	g.Set(10)
+	g.Dec()
+	h := r.NewHistogram("test_latency_seconds", "Latency.", []float64{0.1, 1}, "op")
+	h.Observe(0.05, "read")
+	h.Observe(0.5, "read")
+	h.Observe(5, "read")
+
+	expected := `# HELP test_events_total Events with \\ and\nnewline.
+# TYPE test_events_total counter
+test_events_total{kind="plain"} 2
+test_events_total{kind="quote\"d"} 1
+# HELP test_latency_seconds Latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{op="read",le="0.1"} 1
+test_latency_seconds_bucket{op="read",le="1"} 2
+test_latency_seconds_bucket{op="read",le="+Inf"} 3
+test_latency_seconds_sum{op="read"} 5.55
+test_latency_seconds_count{op="read"} 3
+# HELP test_temperature Current temperature.
+# TYPE test_temperature gauge
+test_temperature 9
+`
+	if got := scrape(t, r); got != expected {
+		t.Fatalf("unexpected exposition:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestRegistration(t *testing.T) {
+	r := NewRegistry()
+	if r.NewCounter("same_total", "Same.", "a") != r.NewCounter("same_total", "Same.", "a") {
+		t.Fatal("registering the same counter twice should return the existing one")
+	}
+
+	for name, register := range map[string]func(){
+		"invalid name":   func() { r.NewGauge("0invalid", "Invalid.") },
+		"missing help":   func() { r.NewGauge("no_help", "") },
+		"conflict":       func() { r.NewGauge("same_total", "Same.", "a") },
+		"reserved le":    func() { r.NewHistogram("reserved", "Reserved.", nil, "le") },
+		"label arity":    func() { r.NewCounter("same_total", "Same.", "a").Inc() },
+		"negative add":   func() { r.NewCounter("same_total", "Same.", "a").Add(-1, "x") },
+		"unsorted":       func() { r.NewHistogram("unsorted", "Unsorted.", []float64{1, 0.1}) },
+		"nil gauge func": func() { r.NewGaugeFunc("nil_func", "Nil.").Track(nil) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected a panic")
+				}
+			}()
+			register()
+		})
+	}
+}
+
+func TestTrackStoreSize(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	kv, err := store.NewMapKeyValue(store.WithRemovalContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = kv.Set(ctx, []byte("key"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRegistry()
+	if !TrackStoreSize(r, "sessions", kv) {
+		t.Fatal("map store should report its size")
+	}
+	if TrackStoreSize(r, "other", struct{}{}) {
+		t.Fatal("unsized value should not be tracked")
+	}
+	if got := scrape(t, r); !strings.Contains(got, `store_values{store="sessions"} 1`) {
+		t.Fatalf("store size is not reported:\n%s", got)
+	}
+}
@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/dkotik/oakhttp"
+)
+
+const (
+	RequestsTotalMetric    = "http_requests_total"
+	RequestDurationMetric  = "http_request_duration_seconds"
+	RequestsInFlightMetric = "http_requests_in_flight"
+	unknownRoute           = "unknown"
+	otherMethod            = "OTHER"
+)
+
+type routeContextKey struct{}
+
+type routeLabel struct {
+	name atomic.Pointer[string]
+}
+
+// SetRoute names the route for the metrics middleware that is handling the request. Returns false if the request is not instrumented.
+func SetRoute(ctx context.Context, name string) bool {
+	label, ok := ctx.Value(routeContextKey{}).(*routeLabel)
+	if !ok {
+		return false
+	}
+	label.name.Store(&name)
+	return true
+}
+
+// Route is a middleware that names the route for the metrics middleware higher up the chain. Place it around individual handlers when the [http.ServeMux] pattern is not a good label.
+func Route(name string) oakhttp.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			SetRoute(r.Context(), name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// routeName prefers explicitly set route over the [http.ServeMux] pattern. Raw paths are never used as labels to keep cardinality bounded.
+func (l *routeLabel) routeName(r *http.Request) string {
+	if name := l.name.Load(); name != nil {
+		return *name
+	}
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	return unknownRoute
+}
+
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodConnect,
+		http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return otherMethod
+	}
+}
+
+func statusClassLabel(statusCode int) string {
+	switch {
+	case statusCode >= 100 && statusCode < 200:
+		return "1xx"
+	case statusCode < 300:
+		return "2xx"
+	case statusCode < 400:
+		return "3xx"
+	case statusCode < 500:
+		return "4xx"
+	case statusCode < 600:
+		return "5xx"
+	default:
+		return "unknown"
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (s *statusRecorder) WriteHeader(statusCode int) {
+	if s.statusCode == 0 && (statusCode < 100 || statusCode > 199 || statusCode == http.StatusSwitchingProtocols) {
+		s.statusCode = statusCode
+	}
+	s.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.statusCode == 0 {
+		s.statusCode = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusRecorder) Flush() {
+	if s.statusCode == 0 {
+		s.statusCode = http.StatusOK
+	}
+	_ = http.NewResponseController(s.ResponseWriter).Flush()
+}
+
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(s.ResponseWriter).Hijack()
+	if err == nil && s.statusCode == 0 {
+		s.statusCode = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap exposes the underlying writer to [http.ResponseController].
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// NewMiddleware records request count by route, method, and status class, request duration by route and method, and requests in flight. Routes are labeled using [SetRoute], [Route], or the matched [http.ServeMux] pattern, in that order. Requests that panic are counted as 5xx before the panic continues up the chain.
+func NewMiddleware(r *Registry) oakhttp.Middleware {
+	if r == nil {
+		panic("cannot use a <nil> metrics registry")
+	}
+	total := r.NewCounter(RequestsTotalMetric, "Total number of HTTP requests handled.", "route", "method", "status_class")
+	duration := r.NewHistogram(RequestDurationMetric, "Duration of HTTP requests in seconds.", DefaultBuckets, "route", "method")
+	inFlight := r.NewGauge(RequestsInFlightMetric, "Number of HTTP requests currently being handled.")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			label := &routeLabel{}
+			r = r.WithContext(context.WithValue(r.Context(), routeContextKey{}, label))
+			recorder := &statusRecorder{ResponseWriter: w}
+			start := time.Now()
+			inFlight.Inc()
+
+			defer func() {
+				inFlight.Dec()
+				statusCode := recorder.statusCode
+				recovered := recover()
+				if recovered != nil {
+					statusCode = http.StatusInternalServerError
+				} else if statusCode == 0 {
+					statusCode = http.StatusOK
+				}
+				route := label.routeName(r)
+				method := methodLabel(r.Method)
+				total.Inc(route, method, statusClassLabel(statusCode))
+				duration.Observe(time.Since(start).Seconds(), route, method)
+				if recovered != nil {
+					panic(recovered)
+				}
+			}()
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
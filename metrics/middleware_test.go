@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	r := NewRegistry()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.Handle("POST /named", Route("named")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	})))
+	mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("test")
+	})
+	h := NewMiddleware(r)(mux)
+
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/users/1", nil),
+		httptest.NewRequest(http.MethodGet, "/users/2", nil),
+		httptest.NewRequest(http.MethodPost, "/named", nil),
+		httptest.NewRequest("PROPFIND", "/missing", nil),
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), request)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic was not propagated")
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	}()
+
+	got := scrape(t, r)
+	for _, expected := range []string{
+		`http_requests_total{route="GET /users/{id}",method="GET",status_class="2xx"} 2`,
+		`http_requests_total{route="named",method="POST",status_class="4xx"} 1`,
+		`http_requests_total{route="unknown",method="OTHER",status_class="4xx"} 1`,
+		`http_requests_total{route="GET /panic",method="GET",status_class="5xx"} 1`,
+		`http_request_duration_seconds_count{route="GET /users/{id}",method="GET"} 2`,
+		`http_requests_in_flight 0`,
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("metric %q is missing from:\n%s", expected, got)
+		}
+	}
+}
+
+func TestMiddlewareFlushes(t *testing.T) {
+	r := NewRegistry()
+	h := NewMiddleware(r)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("response writer does not implement http.Flusher")
+		}
+		_, _ = w.Write([]byte("event"))
+		flusher.Flush()
+		if _, ok = w.(http.Hijacker); !ok {
+			t.Fatal("response writer does not implement http.Hijacker")
+		}
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+	if !w.Flushed {
+		t.Fatal("response was not flushed")
+	}
+	if got := scrape(t, r); !strings.Contains(got, `status_class="2xx"} 1`) {
+		t.Fatalf("flushed response was not counted:\n%s", got)
+	}
+}
@@ -0,0 +1,17 @@
+package metrics
+
+import "github.com/dkotik/oakhttp/store"
+
+const StoreValuesMetric = "store_values"
+
+// TrackStoreSize reports the number of values held by a store under the given name. Stores that do not implement [store.Sized] are ignored and false is returned.
+func TrackStoreSize(r *Registry, name string, s any) bool {
+	sized, ok := s.(store.Sized)
+	if !ok {
+		return false
+	}
+	r.NewGaugeFunc(StoreValuesMetric, "Number of values held by a store.", "store").Track(func() float64 {
+		return float64(sized.Len())
+	}, name)
+	return true
+}
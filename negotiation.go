@@ -0,0 +1,99 @@
+package oakhttp
+
+import (
+	"strconv"
+	"strings"
+)
+
+type acceptedMediaRange struct {
+	Type    string
+	Subtype string
+	Quality float64
+	Order   int
+}
+
+// specificity ranks how closely the range matches a media type: exact match is 2, subtype wildcard is 1, and full wildcard is 0. Returns -1 if the range does not match.
+func (a acceptedMediaRange) specificity(mediaType, subtype string) int {
+	switch {
+	case a.Type == "*" && a.Subtype == "*":
+		return 0
+	case a.Type != mediaType:
+		return -1
+	case a.Subtype == "*":
+		return 1
+	case a.Subtype == subtype:
+		return 2
+	default:
+		return -1
+	}
+}
+
+func parseAcceptHeader(accept string) (ranges []acceptedMediaRange) {
+	for i, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok || mediaType == "" || subtype == "" {
+			continue
+		}
+		accepted := acceptedMediaRange{
+			Type:    mediaType,
+			Subtype: subtype,
+			Quality: 1,
+			Order:   i,
+		}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(strings.ToLower(key)) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err == nil && q >= 0 && q <= 1 {
+				accepted.Quality = q
+			}
+		}
+		ranges = append(ranges, accepted)
+	}
+	return ranges
+}
+
+// NegotiateMediaType picks the offered media type that best satisfies the Accept header value. Offers are ranked by quality, then by the specificity of the matching range, then by their position in the Accept header, and finally by their own order. The first offer is returned when the header is empty. An empty string is returned when none of the offers are acceptable.
+func NegotiateMediaType(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+	ranges := parseAcceptHeader(accept)
+	if len(ranges) == 0 {
+		return offers[0]
+	}
+
+	var (
+		best            string
+		bestQuality     float64
+		bestSpecificity = -1
+		bestOrder       int
+	)
+	for _, offer := range offers {
+		mediaType, subtype, _ := strings.Cut(strings.ToLower(offer), "/")
+		quality, specificity, order := float64(0), -1, 0
+		for _, accepted := range ranges {
+			s := accepted.specificity(mediaType, subtype)
+			if s > specificity {
+				quality, specificity, order = accepted.Quality, s, accepted.Order
+			}
+		}
+		if specificity < 0 || quality == 0 {
+			continue
+		}
+		switch {
+		case best == "",
+			quality > bestQuality,
+			quality == bestQuality && specificity > bestSpecificity,
+			quality == bestQuality && specificity == bestSpecificity && order < bestOrder:
+			best, bestQuality, bestSpecificity, bestOrder = offer, quality, specificity, order
+		}
+	}
+	return best
+}
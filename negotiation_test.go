@@ -0,0 +1,25 @@
+package oakhttp
+
+import "testing"
+
+func TestNegotiateMediaType(t *testing.T) {
+	offers := []string{MediaTypeHTML, MediaTypeProblemJSON, MediaTypeJSON, MediaTypeText}
+	cases := map[string]string{
+		"":                                      MediaTypeHTML,
+		"*/*":                                   MediaTypeHTML,
+		"application/*":                         MediaTypeProblemJSON,
+		"application/json":                      MediaTypeJSON,
+		"APPLICATION/JSON; charset=utf-8":       MediaTypeJSON,
+		"application/json, text/html":           MediaTypeJSON,
+		"text/html;q=0.5, application/json":     MediaTypeJSON,
+		"application/*, application/json;q=0.9": MediaTypeProblemJSON,
+		"text/*;q=0.5, text/plain":              MediaTypeText,
+		"text/html;q=0, */*;q=0.1":              MediaTypeProblemJSON,
+		"image/png":                             "",
+	}
+	for accept, expected := range cases {
+		if mediaType := NegotiateMediaType(accept, offers...); mediaType != expected {
+			t.Errorf("Accept %q negotiated %q instead of %q", accept, mediaType, expected)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package oakhttp
 
 import (
 	"embed"
+	"fmt"
+	"io/fs"
 	"log/slog"
 	"math"
 	"net/http"
@@ -16,8 +18,30 @@ import (
 //go:embed internal/templates
 var Templates embed.FS
 
+// Translations contains message files for the package errors in languages other than English.
+//
+//go:embed internal/translations
+var Translations embed.FS
+
 var LocalizationBundle = i18n.NewBundle(language.AmericanEnglish)
 
+// LoadTranslations adds [Translations] to the localization bundle. The bundle defaults to [LocalizationBundle] when <nil>.
+func LoadTranslations(b *i18n.Bundle) error {
+	if b == nil {
+		b = LocalizationBundle
+	}
+	files, err := fs.Glob(Translations, "internal/translations/*.json")
+	if err != nil {
+		return fmt.Errorf("unable to list translation files: %w", err)
+	}
+	for _, file := range files {
+		if _, err = b.LoadMessageFileFS(Translations, file); err != nil {
+			return fmt.Errorf("unable to load translation file %q: %w", file, err)
+		}
+	}
+	return nil
+}
+
 type Middleware func(http.Handler) http.Handler
 
 // ApplyMiddleware applies [Middleware] in reverse to preserve logical order.
@@ -1,12 +1,17 @@
 package oakhttp
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"runtime"
 )
 
+// DefaultPanicStackSize is the number of bytes reserved for capturing the stack trace of a panic.
+const DefaultPanicStackSize = 10 << 10
+
 type PanicError struct {
 	Cause any
 	Stack string
@@ -28,12 +33,96 @@ func (e *PanicError) LogValue() slog.Value {
 	)
 }
 
+// PanicReporter receives every recovered panic, for example, to forward it to an error tracking service.
+type PanicReporter interface {
+	ReportPanic(context.Context, *PanicError)
+}
+
+type PanicReporterFunc func(context.Context, *PanicError)
+
+func (f PanicReporterFunc) ReportPanic(ctx context.Context, err *PanicError) {
+	f(ctx, err)
+}
+
+type panicOptions struct {
+	Reporter  PanicReporter
+	Logger    *slog.Logger
+	StackSize int
+}
+
+type PanicOption func(*panicOptions) error
+
+func WithPanicReporter(r PanicReporter) PanicOption {
+	return func(o *panicOptions) error {
+		if o.Reporter != nil {
+			return errors.New("panic reporter is already set")
+		}
+		if r == nil {
+			return errors.New("cannot use a <nil> panic reporter")
+		}
+		o.Reporter = r
+		return nil
+	}
+}
+
+// WithPanicLogger sets the logger for panics that cannot be reported to the client because the response was already committed.
+func WithPanicLogger(logger *slog.Logger) PanicOption {
+	return func(o *panicOptions) error {
+		if o.Logger != nil {
+			return errors.New("panic logger is already set")
+		}
+		if logger == nil {
+			return errors.New("cannot use a <nil> structured logger")
+		}
+		o.Logger = logger
+		return nil
+	}
+}
+
+// WithPanicStackSize sets the number of bytes reserved for capturing the stack trace.
+func WithPanicStackSize(bytes int) PanicOption {
+	return func(o *panicOptions) error {
+		if o.StackSize != 0 {
+			return errors.New("panic stack size is already set")
+		}
+		if bytes < 1<<8 {
+			return errors.New("cannot set panic stack size lower than 256 bytes")
+		}
+		if bytes > 1<<24 {
+			return errors.New("panic stack size is too large")
+		}
+		o.StackSize = bytes
+		return nil
+	}
+}
+
 type panicHandler struct {
 	Next         http.Handler
 	ErrorHandler ErrorHandler
+	Reporter     PanicReporter
+	Logger       *slog.Logger
+	StackSize    int
 }
 
-func NewPanicHandler(eh ErrorHandler) Middleware {
+// NewPanicHandler recovers from panics and passes them to the [ErrorHandler] as [PanicError]. If the response was already committed, the panic is logged and the connection is aborted with [http.ErrAbortHandler] instead, because an error page would be appended to a partial response. Panics with [http.ErrAbortHandler] are never recovered.
+func NewPanicHandler(eh ErrorHandler, withOptions ...PanicOption) Middleware {
+	o := &panicOptions{}
+	for _, option := range append(
+		withOptions,
+		func(o *panicOptions) error { // defaults
+			if o.Logger == nil {
+				o.Logger = slog.Default()
+			}
+			if o.StackSize == 0 {
+				o.StackSize = DefaultPanicStackSize
+			}
+			return nil
+		},
+	) {
+		if err := option(o); err != nil {
+			panic(fmt.Errorf("cannot initialize panic handler: %w", err))
+		}
+	}
 	if eh == nil {
 		eh = NewErrorHandler(nil, nil, nil)
 	}
@@ -45,21 +134,51 @@ func NewPanicHandler(eh ErrorHandler) Middleware {
 		return panicHandler{
 			Next:         next,
 			ErrorHandler: eh,
+			Reporter:     o.Reporter,
+			Logger:       o.Logger,
+			StackSize:    o.StackSize,
 		}
 	}
 }
 
 func (h panicHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rw := newResponseWriter(w)
 	defer func() {
-		if recovery := recover(); recovery != nil {
-			buf := make([]byte, 10<<10)
-			n := runtime.Stack(buf, false)
-			h.ErrorHandler.HandleError(w, r, &PanicError{
-				// TODO: would debug.Stack() be better?
-				Cause: recovery,
-				Stack: string(buf[:n]),
-			})
+		recovery := recover()
+		if recovery == nil {
+			return
+		}
+		if err, ok := recovery.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+			panic(recovery)
+		}
+
+		buf := make([]byte, h.StackSize)
+		n := runtime.Stack(buf, false)
+		err := &PanicError{
+			// TODO: would debug.Stack() be better?
+			Cause: recovery,
+			Stack: string(buf[:n]),
+		}
+		if h.Reporter != nil {
+			h.Reporter.ReportPanic(r.Context(), err)
+		}
+		if rw.IsCommitted() {
+			h.Logger.Log(
+				r.Context(),
+				slog.LevelError,
+				"HTTP request panicked after the response was committed",
+				slog.Any("error", err),
+				slog.Int("status_code", rw.StatusCode),
+				slog.Int64("bytes_written", rw.BytesWritten),
+				slog.Group("request",
+					slog.String("host", r.URL.Hostname()),
+					slog.String("path", r.URL.Path),
+					slog.String("method", r.Method),
+				),
+			)
+			panic(http.ErrAbortHandler) // signal the client that the response is broken
 		}
+		h.ErrorHandler.HandleError(rw, r, err)
 	}()
-	h.Next.ServeHTTP(w, r)
+	h.Next.ServeHTTP(rw, r)
 }
@@ -0,0 +1,77 @@
+package oakhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPanicHandler(t *testing.T) {
+	var reported *PanicError
+	mw := NewPanicHandler(
+		NewErrorHandler(nil, nil, nil),
+		WithPanicReporter(PanicReporterFunc(func(ctx context.Context, err *PanicError) {
+			reported = err
+		})),
+		WithPanicStackSize(1<<12),
+	)
+
+	t.Run("recovered", func(t *testing.T) {
+		reported = nil
+		h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("test")
+		}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusInternalServerError {
+			t.Fatal("unexpected status code:", w.Code)
+		}
+		if reported == nil || reported.Cause != "test" {
+			t.Fatal("panic was not reported")
+		}
+		if !strings.Contains(reported.Stack, "goroutine") || len(reported.Stack) > 1<<12 {
+			t.Fatal("unexpected stack trace:", reported.Stack)
+		}
+	})
+
+	t.Run("committed", func(t *testing.T) {
+		reported = nil
+		h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("partial"))
+			panic("test")
+		}))
+		w := httptest.NewRecorder()
+		defer func() {
+			if recovery := recover(); recovery != http.ErrAbortHandler {
+				t.Fatal("committed response was not aborted:", recovery)
+			}
+			if body := w.Body.String(); body != "partial" {
+				t.Fatal("error page was appended to a committed response:", body)
+			}
+			if reported == nil {
+				t.Fatal("panic was not reported")
+			}
+		}()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		reported = nil
+		h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+		defer func() {
+			recovery := recover()
+			if err, ok := recovery.(error); !ok || !errors.Is(err, http.ErrAbortHandler) {
+				t.Fatal("abort panic was swallowed:", recovery)
+			}
+			if reported != nil {
+				t.Fatal("abort panic was reported")
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
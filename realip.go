@@ -0,0 +1,307 @@
+package oakhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+const (
+	ForwardedHeader       = "Forwarded"
+	XForwardedForHeader   = "X-Forwarded-For"
+	XForwardedProtoHeader = "X-Forwarded-Proto"
+	XForwardedHostHeader  = "X-Forwarded-Host"
+)
+
+type clientAddressContextKey struct{}
+
+// ClientAddress describes the client as seen by the outermost trusted proxy.
+type ClientAddress struct {
+	IP     netip.Addr
+	Scheme string
+	Host   string
+}
+
+// ContextWithClientAddress stores the resolved client address for [ClientAddressFromContext].
+func ContextWithClientAddress(parent context.Context, a ClientAddress) context.Context {
+	return context.WithValue(parent, clientAddressContextKey{}, a)
+}
+
+// ClientAddressFromContext returns the client address resolved by [NewRealIPMiddleware].
+func ClientAddressFromContext(ctx context.Context) (ClientAddress, bool) {
+	a, ok := ctx.Value(clientAddressContextKey{}).(ClientAddress)
+	return a, ok
+}
+
+// ClientIP returns the client IP address resolved by [NewRealIPMiddleware]. Without the middleware, it falls back to the address of the network peer. Returns an invalid address if neither is known, such as for requests over unix sockets.
+func ClientIP(r *http.Request) netip.Addr {
+	if a, ok := ClientAddressFromContext(r.Context()); ok {
+		return a.IP
+	}
+	return parseRemoteAddress(r.RemoteAddr)
+}
+
+func parseRemoteAddress(address string) netip.Addr {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	ip, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(address, "["), "]"))
+	if err != nil {
+		return netip.Addr{}
+	}
+	return ip.WithZone("").Unmap()
+}
+
+type realIPOptions struct {
+	TrustedProxies []netip.Prefix
+	Forwarded      bool
+}
+
+type RealIPOption func(*realIPOptions) error
+
+// WithTrustedProxies accepts forwarding headers from peers within the CIDR ranges, like "10.0.0.0/8". Single addresses are accepted as well.
+func WithTrustedProxies(ranges ...string) RealIPOption {
+	return func(o *realIPOptions) error {
+		if len(ranges) == 0 {
+			return errors.New("provide at least one trusted proxy range")
+		}
+		for _, r := range ranges {
+			prefix, err := netip.ParsePrefix(r)
+			if err != nil {
+				ip, ipErr := netip.ParseAddr(r)
+				if ipErr != nil {
+					return fmt.Errorf("invalid trusted proxy range %q: %w", r, err)
+				}
+				prefix = netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen())
+			}
+			prefix = prefix.Masked()
+			if slices.Contains(o.TrustedProxies, prefix) {
+				return fmt.Errorf("trusted proxy range %q is already set", r)
+			}
+			o.TrustedProxies = append(o.TrustedProxies, prefix)
+		}
+		return nil
+	}
+}
+
+// WithForwardedHeader reads the standard Forwarded header defined by RFC 7239 instead of X-Forwarded-For, X-Forwarded-Proto, and X-Forwarded-Host. Choose the header that your proxies set, because clients can send the other one.
+func WithForwardedHeader() RealIPOption {
+	return func(o *realIPOptions) error {
+		if o.Forwarded {
+			return errors.New("forwarded header is already set")
+		}
+		o.Forwarded = true
+		return nil
+	}
+}
+
+// NewRealIPMiddleware resolves the client address from forwarding headers and stores it in the request context for [ClientAddressFromContext] and [ClientIP]. Header values are read from the right, skipping trusted proxies, so that addresses prepended by clients are never believed. Requests from peers that are not trusted proxies are resolved to the peer address.
+func NewRealIPMiddleware(withOptions ...RealIPOption) (Middleware, error) {
+	o := &realIPOptions{}
+	var err error
+	for _, option := range append(
+		withOptions,
+		func(o *realIPOptions) error { // validate
+			if len(o.TrustedProxies) == 0 {
+				return errors.New("WithTrustedProxies option is required")
+			}
+			return nil
+		},
+	) {
+		if err = option(o); err != nil {
+			return nil, fmt.Errorf("cannot initialize real IP middleware: %w", err)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		if next == nil {
+			panic("cannot use a <nil> handler")
+		}
+		return realIPHandler{
+			Next:           next,
+			TrustedProxies: o.TrustedProxies,
+			Forwarded:      o.Forwarded,
+		}
+	}, nil
+}
+
+type realIPHandler struct {
+	Next           http.Handler
+	TrustedProxies []netip.Prefix
+	Forwarded      bool
+}
+
+func (h realIPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Next.ServeHTTP(w, r.WithContext(
+		ContextWithClientAddress(r.Context(), h.resolve(r)),
+	))
+}
+
+func (h realIPHandler) isTrusted(ip netip.Addr) bool {
+	return slices.ContainsFunc(h.TrustedProxies, func(p netip.Prefix) bool {
+		return p.Contains(ip)
+	})
+}
+
+func (h realIPHandler) resolve(r *http.Request) ClientAddress {
+	client := ClientAddress{
+		IP:     parseRemoteAddress(r.RemoteAddr),
+		Scheme: "http",
+		Host:   r.Host,
+	}
+	if r.TLS != nil {
+		client.Scheme = "https"
+	}
+	if !client.IP.IsValid() || !h.isTrusted(client.IP) {
+		return client
+	}
+
+	var hops []forwardedHop
+	if h.Forwarded {
+		hops = parseForwarded(headerList(r.Header, ForwardedHeader))
+	} else {
+		hops = parseXForwarded(
+			headerList(r.Header, XForwardedForHeader),
+			headerList(r.Header, XForwardedProtoHeader),
+			headerList(r.Header, XForwardedHostHeader),
+		)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := hops[i]
+		if !hop.For.IsValid() {
+			break // obfuscated or malformed, cannot tell who sent the rest
+		}
+		client.IP = hop.For
+		if hop.Proto != "" {
+			client.Scheme = hop.Proto
+		}
+		if hop.Host != "" {
+			client.Host = hop.Host
+		}
+		if !h.isTrusted(hop.For) {
+			break
+		}
+	}
+	return client
+}
+
+// forwardedHop is the request as received by one proxy.
+type forwardedHop struct {
+	For   netip.Addr
+	Proto string
+	Host  string
+}
+
+// headerList splits all values of a comma-separated header into trimmed elements.
+func headerList(h http.Header, name string) (list []string) {
+	for _, value := range h.Values(name) {
+		for _, element := range splitQuoted(value, ',') {
+			list = append(list, strings.TrimSpace(element))
+		}
+	}
+	return list
+}
+
+// splitQuoted splits the value by the separator outside of quoted strings.
+func splitQuoted(value string, separator byte) (parts []string) {
+	quoted, escaped, start := false, false, 0
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == separator:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+func unquote(value string) string {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return value
+	}
+	var b strings.Builder
+	escaped := false
+	for _, c := range value[1 : len(value)-1] {
+		if c == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func normalizeScheme(scheme string) string {
+	switch scheme = strings.ToLower(scheme); scheme {
+	case "http", "https":
+		return scheme
+	default:
+		return ""
+	}
+}
+
+// parseForwardedNode reads an IP address from a node identifier, like "192.0.2.43:47011" or "[2001:db8::17]". Returns an invalid address for "unknown" and obfuscated identifiers.
+func parseForwardedNode(node string) netip.Addr {
+	if strings.HasPrefix(node, "[") {
+		if end := strings.IndexByte(node, ']'); end > 0 {
+			node = node[1:end]
+		}
+	} else if host, _, ok := strings.Cut(node, ":"); ok && strings.Count(node, ":") == 1 {
+		node = host
+	}
+	ip, err := netip.ParseAddr(node)
+	if err != nil || ip.Zone() != "" {
+		return netip.Addr{}
+	}
+	return ip.Unmap()
+}
+
+func parseForwarded(elements []string) []forwardedHop {
+	hops := make([]forwardedHop, len(elements))
+	for i, element := range elements {
+		for _, pair := range splitQuoted(element, ';') {
+			key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+			value = unquote(strings.TrimSpace(value))
+			switch strings.ToLower(key) {
+			case "for":
+				hops[i].For = parseForwardedNode(value)
+			case "proto":
+				hops[i].Proto = normalizeScheme(value)
+			case "host":
+				hops[i].Host = value
+			}
+		}
+	}
+	return hops
+}
+
+// parseXForwarded lines up X-Forwarded-Proto and X-Forwarded-Host with X-Forwarded-For when proxies append to all three. Otherwise, the single value set by the nearest proxy applies to every hop.
+func parseXForwarded(addresses, protocols, hosts []string) []forwardedHop {
+	hops := make([]forwardedHop, len(addresses))
+	for i, address := range addresses {
+		hops[i].For = parseForwardedNode(address)
+		if len(protocols) == len(addresses) {
+			hops[i].Proto = normalizeScheme(protocols[i])
+		} else if len(protocols) > 0 {
+			hops[i].Proto = normalizeScheme(protocols[len(protocols)-1])
+		}
+		if len(hosts) == len(addresses) {
+			hops[i].Host = hosts[i]
+		} else if len(hosts) > 0 {
+			hops[i].Host = hosts[len(hosts)-1]
+		}
+	}
+	return hops
+}
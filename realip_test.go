@@ -0,0 +1,154 @@
+package oakhttp
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIPMiddleware(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Forwarded bool
+		Remote    string
+		TLS       bool
+		Header    http.Header
+		Expected  ClientAddress
+	}{
+		{
+			Name:   "untrusted peer",
+			Remote: "203.0.113.9:1234",
+			Header: http.Header{
+				XForwardedForHeader:   {"198.51.100.1"},
+				XForwardedProtoHeader: {"https"},
+			},
+			Expected: ClientAddress{Scheme: "http", Host: "example.com"},
+		},
+		{
+			Name:   "spoofed leftmost address",
+			Remote: "10.0.0.2:1234",
+			Header: http.Header{
+				XForwardedForHeader:   {"1.1.1.1, 198.51.100.1", "10.0.0.1"},
+				XForwardedProtoHeader: {"https"},
+				XForwardedHostHeader:  {"api.example.com"},
+			},
+			Expected: ClientAddress{Scheme: "https", Host: "api.example.com"},
+		},
+		{
+			Name:   "aligned protocols",
+			Remote: "10.0.0.2:1234",
+			Header: http.Header{
+				XForwardedForHeader:   {"198.51.100.1:5555, 10.0.0.1"},
+				XForwardedProtoHeader: {"https, http"},
+			},
+			Expected: ClientAddress{Scheme: "https", Host: "example.com"},
+		},
+		{
+			Name:   "only trusted proxies",
+			Remote: "10.0.0.2:1234",
+			Header: http.Header{
+				XForwardedForHeader: {"10.0.0.3, 10.0.0.1"},
+			},
+			Expected: ClientAddress{Scheme: "https", Host: "example.com"},
+			TLS:      true,
+		},
+		{
+			Name:   "malformed hop",
+			Remote: "10.0.0.2:1234",
+			Header: http.Header{
+				XForwardedForHeader: {"198.51.100.1, garbage, 10.0.0.1"},
+			},
+			Expected: ClientAddress{Scheme: "http", Host: "example.com"},
+		},
+		{
+			Name:      "forwarded",
+			Forwarded: true,
+			Remote:    "[::ffff:10.0.0.2]:1234",
+			Header: http.Header{
+				ForwardedHeader:     {`for=1.1.1.1, For="[2001:db8:cafe::17]:4711";proto=https;host="shop.example.com"`, "for=10.0.0.1;proto=http"},
+				XForwardedForHeader: {"1.1.1.1"},
+			},
+			Expected: ClientAddress{Scheme: "https", Host: "shop.example.com"},
+		},
+		{
+			Name:      "forwarded quoted separators",
+			Forwarded: true,
+			Remote:    "10.0.0.2:1234",
+			Header: http.Header{
+				ForwardedHeader: {`for=198.51.100.1;host="a,b;c", for=_hidden`},
+			},
+			Expected: ClientAddress{Scheme: "http", Host: "example.com"},
+		},
+	}
+	expectedIPs := []string{
+		"203.0.113.9",
+		"198.51.100.1",
+		"198.51.100.1",
+		"10.0.0.3",
+		"10.0.0.1",
+		"2001:db8:cafe::17",
+		"10.0.0.2",
+	}
+
+	for i, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			options := []RealIPOption{WithTrustedProxies("10.0.0.0/8")}
+			if c.Forwarded {
+				options = append(options, WithForwardedHeader())
+			}
+			mw, err := NewRealIPMiddleware(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var resolved ClientAddress
+			h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var ok bool
+				if resolved, ok = ClientAddressFromContext(r.Context()); !ok {
+					t.Fatal("client address is not in the context")
+				}
+				if ClientIP(r) != resolved.IP {
+					t.Fatal("client IP does not match the context value")
+				}
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			r.RemoteAddr = c.Remote
+			r.Header = c.Header
+			if c.TLS {
+				r.TLS = &tls.ConnectionState{}
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+			if resolved.IP.String() != expectedIPs[i] {
+				t.Fatalf("resolved IP %s does not match %s", resolved.IP, expectedIPs[i])
+			}
+			if resolved.Scheme != c.Expected.Scheme || resolved.Host != c.Expected.Host {
+				t.Fatalf("resolved %s://%s does not match %s://%s", resolved.Scheme, resolved.Host, c.Expected.Scheme, c.Expected.Host)
+			}
+		})
+	}
+}
+
+func TestClientIPWithoutMiddleware(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "[2001:db8::1%eth0]:443"
+	if ip := ClientIP(r); ip.String() != "2001:db8::1" {
+		t.Fatal("unexpected peer address:", ip)
+	}
+	r.RemoteAddr = "@"
+	if ip := ClientIP(r); ip.IsValid() {
+		t.Fatal("unix socket peer resolved to an address:", ip)
+	}
+}
+
+func TestRealIPOptions(t *testing.T) {
+	if _, err := NewRealIPMiddleware(); err == nil {
+		t.Fatal("trusted proxies were not required")
+	}
+	if _, err := NewRealIPMiddleware(WithTrustedProxies("10.0.0.0/33")); err == nil {
+		t.Fatal("invalid range was accepted")
+	}
+	if _, err := NewRealIPMiddleware(WithTrustedProxies("10.0.0.1", "::1")); err != nil {
+		t.Fatal("single addresses were not accepted:", err)
+	}
+}
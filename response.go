@@ -0,0 +1,75 @@
+package oakhttp
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// responseWriter tracks whether the response was committed to the client, which status code was sent, and how many body bytes were written.
+type responseWriter struct {
+	http.ResponseWriter
+	StatusCode   int
+	BytesWritten int64
+	WroteHeader  bool
+}
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	if existing, ok := w.(*responseWriter); ok {
+		return existing
+	}
+	return &responseWriter{ResponseWriter: w}
+}
+
+// IsCommitted returns true if the headers were already sent to the client.
+func (w *responseWriter) IsCommitted() bool {
+	return w.WroteHeader
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if !w.WroteHeader {
+		if statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols {
+			w.ResponseWriter.WriteHeader(statusCode) // informational headers do not commit
+			return
+		}
+		w.StatusCode = statusCode
+		w.WroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (n int, err error) {
+	if !w.WroteHeader {
+		w.StatusCode = http.StatusOK
+		w.WroteHeader = true
+	}
+	n, err = w.ResponseWriter.Write(b)
+	w.BytesWritten += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if !w.WroteHeader {
+		w.StatusCode = http.StatusOK
+		w.WroteHeader = true
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		w.WroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap exposes the original writer to [http.ResponseController].
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dkotik/oakhttp/config"
+)
+
+// configurationFields lists settings that can be loaded from the environment or a configuration file.
+var configurationFields = []config.Field[options]{
+	config.Func(func(o *options, values []string) error {
+		port := uint64(DefaultPort)
+		if values[1] != "" {
+			var err error
+			if port, err = strconv.ParseUint(values[1], 10, 16); err != nil {
+				return fmt.Errorf("invalid port %q", values[1])
+			}
+		}
+		return WithAddress(values[0], uint32(port))(o)
+	}, "host", "port"),
+	config.Duration("read_timeout", WithReadTimeout),
+	config.Duration("read_header_timeout", WithReadHeaderTimeout),
+	config.Duration("write_timeout", WithWriteTimeout),
+	config.Duration("idle_timeout", WithIdleTimeout),
+	config.Duration("shutdown_timeout", WithShutdownTimeout),
+	config.Duration("readiness_delay", WithReadinessDelay),
+	config.Int("max_header_bytes", WithMaxHeaderBytes),
+	config.Pair("tls_certificate_file", "tls_key_file", WithTLS),
+	config.Bool("systemd", WithFirstSystemDSocketActivationSocket),
+}
+
+// WithEnvironment loads settings from environment variables with the given prefix: HOST, PORT, READ_TIMEOUT, READ_HEADER_TIMEOUT, WRITE_TIMEOUT, IDLE_TIMEOUT, SHUTDOWN_TIMEOUT, READINESS_DELAY, MAX_HEADER_BYTES, TLS_CERTIFICATE_FILE, TLS_KEY_FILE, and SYSTEMD. Durations use [time.ParseDuration] format. Missing variables are skipped, so that other options or defaults apply.
+//
+//	MYAPP_PORT=8443
+//	MYAPP_READ_TIMEOUT=5s
+//	MYAPP_SYSTEMD=false
+func WithEnvironment(prefix string) Option {
+	return func(o *options) error {
+		if err := config.Apply(o, config.Environment(prefix), configurationFields...); err != nil {
+			return fmt.Errorf("cannot configure server from environment: %w", err)
+		}
+		return nil
+	}
+}
+
+// WithConfigFile loads the same settings as [WithEnvironment] from a JSON configuration file with lower case field names, like "read_timeout". Unknown fields are rejected.
+func WithConfigFile(path string) Option {
+	return func(o *options) error {
+		source, err := config.File(path)
+		if err == nil {
+			err = config.Apply(o, source, configurationFields...)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot configure server from file: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWithEnvironment(t *testing.T) {
+	t.Setenv("OAKTEST_READ_TIMEOUT", "3s")
+	t.Setenv("OAKTEST_MAX_HEADER_BYTES", "4096")
+	o := &options{}
+	if err := WithEnvironment("OAKTEST")(o); err != nil {
+		t.Fatal(err)
+	}
+	if o.ReadTimeout.String() != "3s" || o.MaxHeaderBytes != 4096 {
+		t.Fatalf("settings were not loaded: %+v", o)
+	}
+
+	t.Setenv("OAKTEST_READ_TIMEOUT", "1h")
+	t.Setenv("OAKTEST_WRITE_TIMEOUT", "soon")
+	t.Setenv("OAKTEST_PORT", "99999")
+	err := WithEnvironment("OAKTEST")(&options{})
+	if err == nil {
+		t.Fatal("invalid settings were accepted")
+	}
+	for _, variable := range []string{"OAKTEST_READ_TIMEOUT", "OAKTEST_WRITE_TIMEOUT", "OAKTEST_PORT"} {
+		if !strings.Contains(err.Error(), variable) {
+			t.Errorf("variable %s is missing from error: %s", variable, err)
+		}
+	}
+}
+
+func TestInvalidConfigurationReleasesPort(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+	if err = l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("OAKTEST_HOST", "127.0.0.1")
+	t.Setenv("OAKTEST_PORT", port)
+	t.Setenv("OAKTEST_READ_TIMEOUT", "soon")
+	if err = Run(context.Background(), WithEnvironment("OAKTEST")); err == nil {
+		t.Fatal("invalid read timeout was accepted")
+	}
+	l, err = net.Listen("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		t.Fatal("port was not released:", err)
+	}
+	_ = l.Close()
+}
@@ -0,0 +1,351 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+)
+
+const DefaultProxyProtocolHeaderTimeout = time.Second * 5
+
+// rejectionLogInterval spaces out log entries about rejected connections, so that a connection flood does not turn into a log flood.
+const rejectionLogInterval = time.Second
+
+var errConnectionRejected = errors.New("connection rejected")
+
+type connectionLimitOptions struct {
+	MaximumConnections         int
+	MaximumConnectionsPerIP    int
+	ProxyProtocol              bool
+	ProxyProtocolSources       []netip.Prefix
+	ProxyProtocolHeaderTimeout time.Duration
+}
+
+type ConnectionLimitOption func(*connectionLimitOptions) error
+
+// WithMaximumConnections closes newly accepted connections while the number of open connections across all listeners is at the limit.
+func WithMaximumConnections(n int) ConnectionLimitOption {
+	return func(o *connectionLimitOptions) error {
+		if o.MaximumConnections != 0 {
+			return errors.New("maximum connections limit is already set")
+		}
+		if n < 1 {
+			return errors.New("maximum connections limit must be greater than 0")
+		}
+		o.MaximumConnections = n
+		return nil
+	}
+}
+
+// WithMaximumConnectionsPerIP closes newly accepted connections from a client address that already has the given number of connections open. Behind a load balancer, combine with [WithProxyProtocol] so that clients are told apart.
+func WithMaximumConnectionsPerIP(n int) ConnectionLimitOption {
+	return func(o *connectionLimitOptions) error {
+		if o.MaximumConnectionsPerIP != 0 {
+			return errors.New("maximum connections per IP limit is already set")
+		}
+		if n < 1 {
+			return errors.New("maximum connections per IP limit must be greater than 0")
+		}
+		o.MaximumConnectionsPerIP = n
+		return nil
+	}
+}
+
+// WithProxyProtocol reads the original client address from PROXY protocol version 1 or 2 headers sent by TCP load balancers. When trusted source ranges are given, only connections from them must carry the header and other connections are treated as direct clients. Otherwise, every connection must begin with the header. Never accept the header from untrusted sources, because it lets clients choose their own address.
+func WithProxyProtocol(trusted ...netip.Prefix) ConnectionLimitOption {
+	return func(o *connectionLimitOptions) error {
+		if o.ProxyProtocol {
+			return errors.New("PROXY protocol option is already set")
+		}
+		for _, prefix := range trusted {
+			if !prefix.IsValid() {
+				return fmt.Errorf("invalid PROXY protocol source range: %q", prefix)
+			}
+			o.ProxyProtocolSources = append(o.ProxyProtocolSources, prefix.Masked())
+		}
+		o.ProxyProtocol = true
+		return nil
+	}
+}
+
+// WithProxyProtocolHeaderTimeout closes connections that do not deliver the PROXY protocol header within the duration.
+func WithProxyProtocolHeaderTimeout(d time.Duration) ConnectionLimitOption {
+	return func(o *connectionLimitOptions) error {
+		if o.ProxyProtocolHeaderTimeout != 0 {
+			return errors.New("PROXY protocol header timeout is already set")
+		}
+		if d < time.Millisecond*100 {
+			return errors.New("cannot set PROXY protocol header timeout lower than 100ms")
+		}
+		if d > time.Minute {
+			return errors.New("cannot set PROXY protocol header timeout above one minute")
+		}
+		o.ProxyProtocolHeaderTimeout = d
+		return nil
+	}
+}
+
+// WithConnectionLimits caps the number of open TCP connections in total and per client address across all listeners. Rejected connections are closed without a response and logged at warning level at most once a second. Each entry counts the rejections that were not logged since the previous one. HTTP/3 connections are not counted.
+//
+//	err := server.Run(
+//	  ctx,
+//	  server.WithHandler(application),
+//	  server.WithConnectionLimits(
+//	    server.WithMaximumConnections(10_000),
+//	    server.WithMaximumConnectionsPerIP(64),
+//	    server.WithProxyProtocol(netip.MustParsePrefix("10.0.0.0/8")),
+//	  ),
+//	)
+func WithConnectionLimits(withOptions ...ConnectionLimitOption) Option {
+	return func(o *options) error {
+		if o.ConnectionLimiter != nil {
+			return errors.New("connection limits are already set")
+		}
+		limits := connectionLimitOptions{}
+		for _, option := range append(
+			withOptions,
+			func(o *connectionLimitOptions) error { // validate
+				if o.MaximumConnections == 0 && o.MaximumConnectionsPerIP == 0 && !o.ProxyProtocol {
+					return errors.New("provide at least one connection limit or the PROXY protocol option")
+				}
+				if o.ProxyProtocol && o.ProxyProtocolHeaderTimeout == 0 {
+					o.ProxyProtocolHeaderTimeout = DefaultProxyProtocolHeaderTimeout
+				}
+				return nil
+			},
+		) {
+			if err := option(&limits); err != nil {
+				return fmt.Errorf("cannot configure connection limits: %w", err)
+			}
+		}
+		o.ConnectionLimiter = &connectionLimiter{
+			connectionLimitOptions: limits,
+			perIP:                  make(map[netip.Addr]int),
+		}
+		return nil
+	}
+}
+
+type connectionLimiter struct {
+	connectionLimitOptions
+
+	mu    sync.Mutex
+	total int
+	perIP map[netip.Addr]int
+
+	rejectionLogged     time.Time
+	rejectionSuppressed int
+}
+
+// Wrap counts connections accepted by the listener against the limits.
+func (l *connectionLimiter) Wrap(ln net.Listener, logger *slog.Logger) net.Listener {
+	return &limitedListener{
+		Listener: ln,
+		limiter:  l,
+		logger:   logger,
+	}
+}
+
+func (l *connectionLimiter) acquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.MaximumConnections > 0 && l.total >= l.MaximumConnections {
+		return false
+	}
+	l.total++
+	return true
+}
+
+func (l *connectionLimiter) acquireAddress(address netip.Addr) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.MaximumConnectionsPerIP > 0 && l.perIP[address] >= l.MaximumConnectionsPerIP {
+		return false
+	}
+	l.perIP[address]++
+	return true
+}
+
+// logRejection reports whether a rejection should be logged and how many rejections were not logged since the last entry.
+func (l *connectionLimiter) logRejection() (suppressed int, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if now.Sub(l.rejectionLogged) < rejectionLogInterval {
+		l.rejectionSuppressed++
+		return 0, false
+	}
+	suppressed = l.rejectionSuppressed
+	l.rejectionLogged = now
+	l.rejectionSuppressed = 0
+	return suppressed, true
+}
+
+func (l *connectionLimiter) release(address netip.Addr) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.total--
+	if !address.IsValid() {
+		return
+	}
+	if l.perIP[address] <= 1 {
+		delete(l.perIP, address)
+	} else {
+		l.perIP[address]--
+	}
+}
+
+// expectsProxyProtocol returns true if the connection must begin with a PROXY protocol header.
+func (l *connectionLimiter) expectsProxyProtocol(peer netip.Addr) bool {
+	if !l.ProxyProtocol {
+		return false
+	}
+	if len(l.ProxyProtocolSources) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(l.ProxyProtocolSources, func(p netip.Prefix) bool {
+		return p.Contains(peer)
+	})
+}
+
+type limitedListener struct {
+	net.Listener
+	limiter *connectionLimiter
+	logger  *slog.Logger
+}
+
+func (l *limitedListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if !l.limiter.acquire() {
+			l.reject(conn, conn.RemoteAddr(), "too many open connections")
+			continue
+		}
+		c := &limitedConn{
+			Conn:     conn,
+			listener: l,
+			remote:   conn.RemoteAddr(),
+		}
+		if l.limiter.expectsProxyProtocol(addressOf(c.remote)) {
+			// the header is read from the connection goroutine
+			// so that slow clients do not hold up the accept loop
+			c.reader = bufio.NewReader(conn)
+			return c, nil
+		}
+		c.once.Do(func() { c.admit() })
+		if c.err != nil {
+			continue
+		}
+		return c, nil
+	}
+}
+
+func (l *limitedListener) reject(conn net.Conn, remote net.Addr, reason string) {
+	if suppressed, ok := l.limiter.logRejection(); ok {
+		l.logger.Warn(
+			"rejected network connection",
+			slog.String("remote_address", remote.String()),
+			slog.String("reason", reason),
+			slog.Int("suppressed", suppressed),
+		)
+	}
+	_ = conn.Close()
+}
+
+// limitedConn releases its place in the connection limits when closed. It reports the client address from the PROXY protocol header as its remote address.
+type limitedConn struct {
+	net.Conn
+	listener *limitedListener
+	reader   *bufio.Reader
+	once     sync.Once
+	remote   net.Addr
+	address  netip.Addr
+	err      error
+	closed   sync.Once
+}
+
+func (c *limitedConn) handshake() error {
+	c.once.Do(func() {
+		if err := c.SetReadDeadline(time.Now().Add(c.listener.limiter.ProxyProtocolHeaderTimeout)); err != nil {
+			c.err = err
+			c.listener.reject(c, c.remote, "cannot set PROXY protocol header deadline")
+			return
+		}
+		remote, err := readProxyProtocolHeader(c.reader)
+		if err != nil {
+			c.err = err
+			c.listener.reject(c, c.remote, err.Error())
+			return
+		}
+		if err = c.SetReadDeadline(time.Time{}); err != nil {
+			c.err = err
+			c.listener.reject(c, c.remote, "cannot reset PROXY protocol header deadline")
+			return
+		}
+		if remote != nil { // nil for proxy health checks
+			c.remote = remote
+		}
+		c.admit()
+	})
+	return c.err
+}
+
+func (c *limitedConn) admit() {
+	address := addressOf(c.remote)
+	if !address.IsValid() {
+		return // unix sockets are only limited in total
+	}
+	if !c.listener.limiter.acquireAddress(address) {
+		c.err = errConnectionRejected
+		c.listener.reject(c, c.remote, "too many open connections from address")
+		return
+	}
+	c.address = address
+}
+
+func (c *limitedConn) Read(b []byte) (int, error) {
+	if err := c.handshake(); err != nil {
+		return 0, err
+	}
+	if c.reader != nil {
+		return c.reader.Read(b)
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *limitedConn) Write(b []byte) (int, error) {
+	if err := c.handshake(); err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(b)
+}
+
+func (c *limitedConn) RemoteAddr() net.Addr {
+	_ = c.handshake()
+	return c.remote
+}
+
+func (c *limitedConn) Close() (err error) {
+	err = net.ErrClosed
+	c.closed.Do(func() {
+		err = c.Conn.Close()
+		c.listener.limiter.release(c.address)
+	})
+	return err
+}
+
+func addressOf(a net.Addr) netip.Addr {
+	if tcp, ok := a.(*net.TCPAddr); ok {
+		return tcp.AddrPort().Addr().Unmap()
+	}
+	return netip.Addr{}
+}
@@ -0,0 +1,257 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadProxyProtocolHeader(t *testing.T) {
+	v2 := func(command, family byte, addresses []byte) string {
+		b := append([]byte{}, proxyProtocolSignature...)
+		b = append(b, 0x20|command, family)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(addresses)))
+		return string(append(b, addresses...))
+	}
+	ipv4 := append(netip.MustParseAddr("203.0.113.7").AsSlice(), 10, 0, 0, 1, 0x30, 0x39, 0, 80)
+	ipv6 := append(netip.MustParseAddr("2001:db8::7").AsSlice(), netip.MustParseAddr("2001:db8::1").AsSlice()...)
+	ipv6 = append(ipv6, 0x30, 0x39, 0, 80)
+
+	cases := []struct {
+		Name    string
+		Header  string
+		Address string
+		Error   bool
+	}{
+		{Name: "v1 IPv4", Header: "PROXY TCP4 203.0.113.7 10.0.0.1 12345 80\r\n", Address: "203.0.113.7:12345"},
+		{Name: "v1 IPv6", Header: "PROXY TCP6 2001:db8::7 2001:db8::1 12345 80\r\n", Address: "[2001:db8::7]:12345"},
+		{Name: "v1 unknown", Header: "PROXY UNKNOWN\r\n"},
+		{Name: "v1 family mismatch", Header: "PROXY TCP4 2001:db8::7 2001:db8::1 12345 80\r\n", Error: true},
+		{Name: "v1 without CRLF", Header: "PROXY TCP4 203.0.113.7 10.0.0.1 12345 80\n", Error: true},
+		{Name: "v1 too long", Header: "PROXY " + strings.Repeat("A", 120), Error: true},
+		{Name: "v2 IPv4", Header: v2(1, 0x11, ipv4), Address: "203.0.113.7:12345"},
+		{Name: "v2 IPv6 with TLV", Header: v2(1, 0x21, append(ipv6, 0x04, 0, 1, 'x')), Address: "[2001:db8::7]:12345"},
+		{Name: "v2 local", Header: v2(0, 0x00, nil)},
+		{Name: "v2 truncated", Header: v2(1, 0x11, ipv4[:6]), Error: true},
+		{Name: "missing", Header: "GET / HTTP/1.1\r\n\r\n", Error: true},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(c.Header + "GET"))
+			address, err := readProxyProtocolHeader(r)
+			if c.Error {
+				if err == nil {
+					t.Fatal("invalid header was accepted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Address == "" && address != nil {
+				t.Fatal("unexpected address:", address)
+			}
+			if c.Address != "" && (address == nil || address.String() != c.Address) {
+				t.Fatalf("address %v does not match %q", address, c.Address)
+			}
+			if rest, _ := io.ReadAll(r); string(rest) != "GET" {
+				t.Fatalf("header consumed too much or too little: %q", rest)
+			}
+		})
+	}
+}
+
+type lockedBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func newTestConnectionLimiter(t *testing.T, withOptions ...ConnectionLimitOption) (net.Listener, *lockedBuffer) {
+	t.Helper()
+	o := &options{}
+	if err := WithConnectionLimits(withOptions...)(o); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	log := &lockedBuffer{}
+	return o.ConnectionLimiter.Wrap(ln, slog.New(slog.NewTextHandler(log, nil))), log
+}
+
+func TestConnectionLimits(t *testing.T) {
+	ln, log := newTestConnectionLimiter(t,
+		WithMaximumConnections(3),
+		WithMaximumConnectionsPerIP(2),
+	)
+	accepted := make(chan net.Conn)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				close(accepted)
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	dial := func() net.Conn {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		return conn
+	}
+	isClosed := func(conn net.Conn) bool {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Millisecond * 200))
+		_, err := conn.Read(make([]byte, 1))
+		return err == io.EOF
+	}
+
+	dial()
+	dial()
+	first := <-accepted
+	<-accepted
+	if rejected := dial(); !isClosed(rejected) {
+		t.Fatal("connection over the per IP limit was not closed")
+	}
+	if !strings.Contains(log.String(), "too many open connections from address") {
+		t.Fatal("rejected connection was not logged:", log.String())
+	}
+
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Close(); err == nil {
+		t.Fatal("closing twice did not fail")
+	}
+	dial()
+	select {
+	case <-accepted:
+	case <-time.After(time.Second):
+		t.Fatal("connection was not accepted after another one closed")
+	}
+}
+
+func TestConnectionLimitsWithProxyProtocol(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errChannel := make(chan error)
+	go func() {
+		errChannel <- Run(ctx,
+			WithListener(ln),
+			WithConnectionLimits(
+				WithMaximumConnectionsPerIP(1),
+				WithProxyProtocol(netip.MustParsePrefix("127.0.0.0/8")),
+				WithProxyProtocolHeaderTimeout(time.Millisecond*200),
+			),
+			WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.WriteString(w, r.RemoteAddr)
+			})),
+		)
+	}()
+	defer func() {
+		cancel()
+		if err := <-errChannel; err != nil {
+			t.Fatal("server shut down with an error:", err)
+		}
+	}()
+	time.Sleep(time.Millisecond * 50)
+
+	request := func(header string) (string, error) {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		if _, err = io.WriteString(conn, header+"GET / HTTP/1.1\r\nHost: test\r\n\r\n"); err != nil {
+			return "", err
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		return string(b), err
+	}
+
+	remote, err := request("PROXY TCP4 203.0.113.7 10.0.0.1 12345 80\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remote != "203.0.113.7:12345" {
+		t.Fatal("client address was not taken from the PROXY header:", remote)
+	}
+	if _, err = request("PROXY TCP4 203.0.113.7 10.0.0.1 12346 80\r\n"); err == nil {
+		t.Fatal("second connection from the same client was not rejected")
+	}
+	if remote, err = request("PROXY TCP4 203.0.113.8 10.0.0.1 12345 80\r\n"); err != nil || remote != "203.0.113.8:12345" {
+		t.Fatal("connection from another client was not served:", remote, err)
+	}
+	if _, err = request(""); err == nil {
+		t.Fatal("connection without a PROXY header was served")
+	}
+}
+
+func TestConnectionRejectionLogIsRateLimited(t *testing.T) {
+	ln, log := newTestConnectionLimiter(t, WithMaximumConnections(1))
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	held, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Close()
+	<-accepted
+	for range 20 {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, _ = conn.Read(make([]byte, 1)) // wait for rejection
+		_ = conn.Close()
+	}
+	if n := strings.Count(log.String(), "rejected network connection"); n != 1 {
+		t.Fatalf("%d rejections were logged instead of one:\n%s", n, log.String())
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-systemd/activation"
+)
+
+const (
+	// upgradeParentEnvironmentVariable carries the process identifier of the parent that passed listeners during a binary upgrade. The parent cannot set LISTEN_PID, because the child process identifier is not known before it starts.
+	upgradeParentEnvironmentVariable = "OAKHTTP_UPGRADE_PARENT_PID"
+	// upgradeReadyEnvironmentVariable carries the file descriptor that the child closes after it starts serving.
+	upgradeReadyEnvironmentVariable = "OAKHTTP_UPGRADE_READY_FD"
+)
+
+type inheritedListener struct {
+	Name     string
+	Listener net.Listener
+	Taken    bool
+}
+
+type inheritedListeners struct {
+	mu        sync.Mutex
+	listeners []*inheritedListener
+	err       error
+}
+
+// inherited holds listeners passed by systemd socket activation or by the parent process during a binary upgrade. Both use the LISTEN_FDS protocol. The environment variables are unset after the first read, so the listeners are cached for the life of the process.
+var inherited = sync.OnceValue(func() *inheritedListeners {
+	if parent := os.Getenv(upgradeParentEnvironmentVariable); parent != "" {
+		if parent == strconv.Itoa(os.Getppid()) {
+			_ = os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+		}
+		_ = os.Unsetenv(upgradeParentEnvironmentVariable)
+	}
+
+	result := &inheritedListeners{}
+	for _, file := range activation.Files(true) {
+		ln, err := net.FileListener(file)
+		if err != nil {
+			result.err = errors.Join(result.err, fmt.Errorf("cannot use inherited socket %q as a listener: %w", file.Name(), err))
+		} else {
+			result.listeners = append(result.listeners, &inheritedListener{
+				Name:     file.Name(),
+				Listener: ln,
+			})
+		}
+		_ = file.Close() // [net.FileListener] holds a duplicate
+	}
+	return result
+})
+
+// Take returns the first unused inherited listener with the given name. Any name matches when the name is empty.
+func (i *inheritedListeners) Take(name string) (net.Listener, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, l := range i.listeners {
+		if !l.Taken && (name == "" || l.Name == name) {
+			l.Taken = true
+			return l.Listener, true
+		}
+	}
+	return nil, false
+}
+
+// TakeAll returns every unused inherited listener with the given name, including the ones that were numbered by [WithSystemDSocketActivationSocket] before a binary upgrade.
+func (i *inheritedListeners) TakeAll(name string) (result []net.Listener) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, l := range i.listeners {
+		if !l.Taken && (l.Name == name || strings.HasPrefix(l.Name, name+"#")) {
+			l.Taken = true
+			result = append(result, l.Listener)
+		}
+	}
+	return result
+}
+
+// listen prefers an inherited listener with the same name to binding a new one, so that a process started by a binary upgrade reuses the sockets of its parent.
+func listen(name, network, address string) (net.Listener, error) {
+	if ln, ok := inherited().Take(name); ok {
+		return ln, nil
+	}
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot bind listener to %q address: %w", address, err)
+	}
+	return ln, nil
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/dkotik/oakhttp"
+)
+
+// DefaultListenerName identifies the listener set by [WithListener] or [WithAddress] in logs and errors.
+const DefaultListenerName = "default"
+
+type listenerOptions struct {
+	Handler            http.Handler
+	TLSConfig          *tls.Config
+	CertificateManager *CertificateManager
+}
+
+// ListenerOption configures a named listener.
+type ListenerOption func(*listenerOptions) error
+
+// WithListenerHandler serves the listener with its own handler instead of the one set by [WithHandler]. The handler is protected from panics the same way.
+func WithListenerHandler(h http.Handler) ListenerOption {
+	return func(o *listenerOptions) error {
+		if h == nil {
+			return errors.New("cannot use a <nil> handler")
+		}
+		if o.Handler != nil {
+			return errors.New("listener handler is already set")
+		}
+		o.Handler = h
+		return nil
+	}
+}
+
+// WithListenerTLS serves the listener over TLS using [NewTLSConfig] defaults. The certificate is reloaded when its files change.
+func WithListenerTLS(certificateFile, keyFile string) ListenerOption {
+	return func(o *listenerOptions) error {
+		m, err := NewCertificateManager(WithCertificateKeyPair(certificateFile, keyFile))
+		if err != nil {
+			return err
+		}
+		return WithListenerCertificateManager(m)(o)
+	}
+}
+
+// WithListenerCertificateManager serves the listener over TLS with certificates selected by server name. The manager is watched for certificate changes while the server runs.
+func WithListenerCertificateManager(m *CertificateManager, withOptions ...TLSOption) ListenerOption {
+	return func(o *listenerOptions) error {
+		config, err := NewTLSConfig(m, withOptions...)
+		if err != nil {
+			return err
+		}
+		if err = WithListenerTLSConfig(config)(o); err != nil {
+			return err
+		}
+		o.CertificateManager = m
+		return nil
+	}
+}
+
+// WithListenerTLSConfig serves the listener over TLS with a custom configuration.
+func WithListenerTLSConfig(config *tls.Config) ListenerOption {
+	return func(o *listenerOptions) error {
+		if o.TLSConfig != nil {
+			return errors.New("listener TLS option is already set")
+		}
+		if config == nil {
+			return errors.New("cannot use a <nil> TLS configuration")
+		}
+		o.TLSConfig = config
+		return nil
+	}
+}
+
+type namedListener struct {
+	listenerOptions
+	Name     string
+	Listener net.Listener
+}
+
+// WithNamedListener serves an additional listener under the same life cycle as the default listener. All listeners start together, turn not ready together, and drain within the same shutdown timeout. The default listener is still bound, unless every named listener serves the default handler and TLS is not configured, which is the case for systemd socket activation.
+//
+//	err := server.Run(
+//	  ctx,
+//	  server.WithHandler(application),
+//	  server.WithTLS("cert.pem", "key.pem"),
+//	  server.WithNamedAddress("redirect", "tcp", ":80",
+//	    server.WithListenerHandler(redirect)),
+//	  server.WithNamedAddress("admin", "unix", "/run/myapp/admin.sock",
+//	    server.WithListenerHandler(admin)),
+//	)
+func WithNamedListener(name string, l net.Listener, withOptions ...ListenerOption) Option {
+	return func(o *options) (err error) {
+		if name == "" {
+			return errors.New("listener name is required")
+		}
+		if name == DefaultListenerName {
+			return fmt.Errorf("listener name %q is reserved", name)
+		}
+		if l == nil {
+			return errors.New("cannot use a <nil> network listener")
+		}
+		for _, existing := range o.Listeners {
+			if existing.Name == name {
+				return fmt.Errorf("listener %q is already set", name)
+			}
+		}
+
+		named := namedListener{Name: name, Listener: l}
+		for _, option := range withOptions {
+			if err = option(&named.listenerOptions); err != nil {
+				return fmt.Errorf("cannot configure listener %q: %w", name, err)
+			}
+		}
+		if named.Handler != nil {
+			var panicOptions []oakhttp.PanicOption
+			if o.Logger != nil {
+				panicOptions = append(panicOptions, oakhttp.WithPanicLogger(o.Logger))
+			}
+			named.Handler = oakhttp.NewPanicHandler(
+				oakhttp.NewErrorHandler(nil, nil, o.Logger),
+				panicOptions...,
+			)(named.Handler)
+		}
+		o.Listeners = append(o.Listeners, named)
+		return nil
+	}
+}
+
+// WithNamedAddress binds a network listener and serves it using [WithNamedListener]. Network is "tcp", "tcp4", "tcp6", or "unix". A listener with the same name passed down by a binary upgrade is used instead of binding a new one.
+func WithNamedAddress(name, network, address string, withOptions ...ListenerOption) Option {
+	return func(o *options) error {
+		listener, err := listen(name, network, address)
+		if err != nil {
+			return err
+		}
+		if err = WithNamedListener(name, listener, withOptions...)(o); err != nil {
+			return errors.Join(err, listener.Close())
+		}
+		return nil
+	}
+}
+
+// needsDefaultListener reports whether the default handler or TLS configuration would go unserved without binding the default listener. Named listeners without their own handler serve the default handler, but never use the default TLS configuration.
+func (o *options) needsDefaultListener() bool {
+	if o.Listener != nil {
+		return false
+	}
+	if len(o.Listeners) == 0 || o.TLSConfig != nil {
+		return true
+	}
+	for _, named := range o.Listeners {
+		if named.Handler == nil {
+			return false
+		}
+	}
+	return o.Handler != nil
+}
+
+// closeListeners releases the sockets bound by options, when a later option fails.
+func (o *options) closeListeners() {
+	if o.Listener != nil {
+		_ = o.Listener.Close()
+	}
+	for _, named := range o.Listeners {
+		_ = named.Listener.Close()
+	}
+}
+
+// listeners returns the default listener followed by named listeners.
+func (o *options) listeners() (result []namedListener) {
+	if o.Listener != nil {
+		result = append(result, namedListener{
+			Name:     DefaultListenerName,
+			Listener: o.Listener,
+			listenerOptions: listenerOptions{
+				Handler:            o.Handler,
+				TLSConfig:          o.TLSConfig,
+				CertificateManager: o.CertificateManager,
+			},
+		})
+	}
+	for _, named := range o.Listeners {
+		if named.Handler == nil {
+			named.Handler = o.Handler
+		}
+		result = append(result, named)
+	}
+	if o.StrictTransportSecurity != nil {
+		for i := range result {
+			if result[i].TLSConfig != nil && result[i].Handler != nil {
+				result[i].Handler = o.StrictTransportSecurity(result[i].Handler)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNamedListeners(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	main, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(t.TempDir(), "server.sock")
+
+	errChannel := make(chan error)
+	go func() {
+		errChannel <- Run(
+			ctx,
+			WithListener(main),
+			WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			})),
+			WithNamedListener("admin", admin, WithListenerHandler(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusTeapot)
+				}),
+			)),
+			WithNamedAddress("internal", "unix", socket),
+		)
+	}()
+	time.Sleep(time.Millisecond * 50)
+
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	for name, request := range map[string]struct {
+		Client     *http.Client
+		URL        string
+		StatusCode int
+	}{
+		"default":  {http.DefaultClient, "http://" + main.Addr().String(), http.StatusAccepted},
+		"admin":    {http.DefaultClient, "http://" + admin.Addr().String(), http.StatusTeapot},
+		"internal": {unixClient, "http://unix", http.StatusAccepted},
+	} {
+		resp, err := request.Client.Get(request.URL)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != request.StatusCode {
+			t.Errorf("listener %q responded with unexpected status code: %d", name, resp.StatusCode)
+		}
+	}
+
+	cancel()
+	if err = <-errChannel; err != nil {
+		t.Fatal("server shut down with an error:", err)
+	}
+}
+
+// newInheritedTestListener passes a listener to the next [listen] call with the given name, the same way a binary upgrade does, so that tests can exercise default addresses like :443 without binding them.
+func newInheritedTestListener(t *testing.T, name string) net.Listener {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := inherited()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.listeners = append(i.listeners, &inheritedListener{Name: name, Listener: ln})
+	return ln
+}
+
+func TestNamedListenersKeepDefaultListener(t *testing.T) {
+	certificate := newTestServerCertificate(t, "localhost")
+	main := newInheritedTestListener(t, DefaultListenerName)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errChannel := make(chan error)
+	go func() {
+		errChannel <- Run(
+			ctx,
+			WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			})),
+			WithTLS(certificate.CertificateFile, certificate.KeyFile),
+			WithNamedAddress("admin", "tcp", "127.0.0.1:0", WithListenerHandler(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusTeapot)
+				}),
+			)),
+		)
+	}()
+	time.Sleep(time.Millisecond * 50)
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: newTestClientTLSConfig(certificate),
+	}}
+	resp, err := client.Get("https://" + main.Addr().String())
+	if err != nil {
+		t.Fatal("default listener is not serving:", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatal("unexpected status code:", resp.StatusCode)
+	}
+
+	cancel()
+	if err = <-errChannel; err != nil {
+		t.Fatal("server shut down with an error:", err)
+	}
+}
+
+func TestNamedListenerValidation(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	o := &options{}
+	if err = WithNamedListener("admin", ln)(o); err != nil {
+		t.Fatal(err)
+	}
+	for name, option := range map[string]Option{
+		"duplicate": WithNamedListener("admin", ln),
+		"reserved":  WithNamedListener(DefaultListenerName, ln),
+		"unnamed":   WithNamedListener("", ln),
+		"nil":       WithNamedListener("other", nil),
+		"bad TLS":   WithNamedListener("other", ln, WithListenerTLS("", "")),
+	} {
+		if option(o) == nil {
+			t.Errorf("option %q should have failed", name)
+		}
+	}
+
+	err = Run(context.Background(), WithNamedListener("admin", ln))
+	if err == nil {
+		t.Fatal("server without handlers should not start")
+	}
+}
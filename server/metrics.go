@@ -0,0 +1,63 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/dkotik/oakhttp/metrics"
+)
+
+const (
+	ConnectionsMetric      = "http_server_connections"
+	ConnectionsTotalMetric = "http_server_connections_total"
+)
+
+// WithMetrics reports open connections by state and the total number of accepted connections to the registry.
+func WithMetrics(r *metrics.Registry) Option {
+	return func(o *options) error {
+		if r == nil {
+			return errors.New("cannot use a <nil> metrics registry")
+		}
+		if o.Metrics != nil {
+			return errors.New("metrics registry is already set")
+		}
+		o.Metrics = r
+		return nil
+	}
+}
+
+type connectionMetrics struct {
+	mu     sync.Mutex
+	states map[net.Conn]http.ConnState
+	open   *metrics.Gauge
+	total  *metrics.Counter
+}
+
+func newConnectionMetrics(r *metrics.Registry) *connectionMetrics {
+	return &connectionMetrics{
+		states: make(map[net.Conn]http.ConnState),
+		open:   r.NewGauge(ConnectionsMetric, "Number of open connections by state.", "state"),
+		total:  r.NewCounter(ConnectionsTotalMetric, "Total number of accepted connections."),
+	}
+}
+
+// ConnState is an [http.Server.ConnState] hook that moves the connection between state gauges. Hijacked and closed connections are no longer tracked.
+func (c *connectionMetrics) ConnState(conn net.Conn, state http.ConnState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if previous, ok := c.states[conn]; ok {
+		c.open.Dec(previous.String())
+	} else if state == http.StateNew {
+		c.total.Inc()
+	}
+	switch state {
+	case http.StateHijacked, http.StateClosed:
+		delete(c.states, conn)
+	default:
+		c.states[conn] = state
+		c.open.Inc(state.String())
+	}
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dkotik/oakhttp/metrics"
+)
+
+func TestConnectionMetrics(t *testing.T) {
+	r := metrics.NewRegistry()
+	c := newConnectionMetrics(r)
+	first, second := &net.TCPConn{}, &net.TCPConn{}
+	c.ConnState(first, http.StateNew)
+	c.ConnState(first, http.StateActive)
+	c.ConnState(second, http.StateNew)
+	c.ConnState(second, http.StateActive)
+	c.ConnState(second, http.StateIdle)
+	c.ConnState(first, http.StateClosed)
+
+	b := &bytes.Buffer{}
+	w := bufio.NewWriter(b)
+	r.Write(w)
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`http_server_connections{state="active"} 0`,
+		`http_server_connections{state="idle"} 1`,
+		`http_server_connections{state="new"} 0`,
+		`http_server_connections_total 2`,
+	} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("metric %q is missing from:\n%s", expected, b.String())
+		}
+	}
+}
@@ -2,15 +2,19 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/dkotik/oakhttp"
+	"github.com/dkotik/oakhttp/metrics"
 	"github.com/dkotik/oakhttp/token"
+	"golang.org/x/net/http2"
 )
 
 type ContextFactory func(net.Listener) context.Context
@@ -25,8 +29,8 @@ const (
 )
 
 type options struct {
-	TLSCertificateFile string
-	TLSKeyFile         string
+	TLSConfig          *tls.Config
+	CertificateManager *CertificateManager
 	ReadTimeout        time.Duration
 	ReadHeaderTimeout  time.Duration
 	WriteTimeout       time.Duration
@@ -34,8 +38,20 @@ type options struct {
 	MaxHeaderBytes     int
 	Logger             *slog.Logger
 	Listener           net.Listener
+	Listeners          []namedListener
 	ContextFactory     ContextFactory
 	Handler            http.Handler
+	Metrics            *metrics.Registry
+	ShutdownTimeout    time.Duration
+	ReadinessDelay     time.Duration
+	Readiness          *Readiness
+	ShutdownHooks      []ShutdownHook
+	UpgradeSignal      os.Signal
+	HTTP2              *http2.Server
+	HTTP3              bool
+	ConnectionLimiter  *connectionLimiter
+
+	StrictTransportSecurity oakhttp.Middleware
 }
 type Option func(*options) error
 
@@ -46,7 +62,7 @@ func WithDebugOptions() Option {
 				return err
 			}
 		}
-		if o.Listener == nil {
+		if o.needsDefaultListener() {
 			if err = WithAddress("localhost", 8080)(o); err != nil {
 				return err
 			}
@@ -88,8 +104,18 @@ func WithDefaultOptions() Option {
 				return err
 			}
 		}
-		if o.Listener == nil {
-			if o.TLSCertificateFile != "" {
+		if o.ShutdownTimeout == 0 {
+			if err = WithShutdownTimeout(DefaultShutdownTimeout)(o); err != nil {
+				return err
+			}
+		}
+		if o.Readiness == nil {
+			if err = WithReadiness(&Readiness{})(o); err != nil {
+				return err
+			}
+		}
+		if o.needsDefaultListener() {
+			if o.TLSConfig != nil {
 				if err = WithAddress("", 443)(o); err != nil {
 					return err
 				}
@@ -216,12 +242,17 @@ func WithAddress(host string, port uint32) Option {
 		if port < 1 {
 			return errors.New("cannot use port lower than 1")
 		}
-		address := fmt.Sprintf("%s:%d", host, port)
-		listener, err := net.Listen("tcp", address)
+		if o.Listener != nil {
+			return errors.New("address is already set")
+		}
+		listener, err := listen(DefaultListenerName, "tcp", fmt.Sprintf("%s:%d", host, port))
 		if err != nil {
-			return fmt.Errorf("cannot bind listener to %q address: %w", address, err)
+			return err
+		}
+		if err = WithListener(listener)(o); err != nil {
+			return errors.Join(err, listener.Close())
 		}
-		return WithListener(listener)(o)
+		return nil
 	}
 }
 
@@ -268,13 +299,49 @@ func WithDefaultTraceIDGenerator() Option {
 	}
 }
 
+// WithTLS serves the default listener over TLS using [NewTLSConfig] defaults. The certificate is reloaded when its files change. Use [WithCertificateManager] to serve several certificates or to require client certificates.
 func WithTLS(certificateFile, keyFile string) Option {
 	return func(o *options) error {
-		if o.TLSCertificateFile != "" {
+		var managerOptions []CertificateManagerOption
+		if o.Logger != nil {
+			managerOptions = append(managerOptions, WithCertificateLogger(o.Logger))
+		}
+		m, err := NewCertificateManager(append(
+			managerOptions,
+			WithCertificateKeyPair(certificateFile, keyFile),
+		)...)
+		if err != nil {
+			return err
+		}
+		return WithCertificateManager(m)(o)
+	}
+}
+
+// WithCertificateManager serves the default listener over TLS with certificates selected by server name. The manager is watched for certificate changes while the server runs.
+func WithCertificateManager(m *CertificateManager, withOptions ...TLSOption) Option {
+	return func(o *options) error {
+		config, err := NewTLSConfig(m, withOptions...)
+		if err != nil {
+			return err
+		}
+		if err = WithTLSConfig(config)(o); err != nil {
+			return err
+		}
+		o.CertificateManager = m
+		return nil
+	}
+}
+
+// WithTLSConfig serves the default listener over TLS with a custom configuration. It must provide certificates using either [tls.Config.Certificates] or [tls.Config.GetCertificate].
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) error {
+		if o.TLSConfig != nil {
 			return errors.New("TLS option is already set")
 		}
-		o.TLSCertificateFile = certificateFile
-		o.TLSKeyFile = keyFile
+		if config == nil {
+			return errors.New("cannot use a <nil> TLS configuration")
+		}
+		o.TLSConfig = config
 		return nil
 	}
 }
@@ -297,8 +364,13 @@ func WithHandler(h http.Handler) Option {
 		if h == nil {
 			return errors.New("cannot use a <nil> handler")
 		}
+		var panicOptions []oakhttp.PanicOption
+		if o.Logger != nil {
+			panicOptions = append(panicOptions, oakhttp.WithPanicLogger(o.Logger))
+		}
 		return WithUnsafeHandler(oakhttp.NewPanicHandler(
 			oakhttp.NewErrorHandler(nil, nil, o.Logger),
+			panicOptions...,
 		)(h))(o)
 	}
 }
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+const (
+	DefaultHTTP2MaxConcurrentStreams = 250
+	DefaultHTTP2ReadIdleTimeout      = time.Second * 30
+	DefaultHTTP2PingTimeout          = time.Second * 15
+)
+
+// gracefulServer is implemented by [http.Server] and [http3.Server].
+type gracefulServer interface {
+	Shutdown(context.Context) error
+	Close() error
+}
+
+type HTTP2Option func(*http2.Server) error
+
+// WithHTTP2MaxConcurrentStreams limits how many requests a single client connection may have in flight.
+func WithHTTP2MaxConcurrentStreams(n uint32) HTTP2Option {
+	return func(s *http2.Server) error {
+		if n < 1 {
+			return errors.New("HTTP/2 concurrent stream limit must be greater than 0")
+		}
+		if n > 10_000 {
+			return errors.New("HTTP/2 concurrent stream limit greater than 10000 is unreasonable")
+		}
+		s.MaxConcurrentStreams = n
+		return nil
+	}
+}
+
+// WithHTTP2ReadIdleTimeout sends a health check ping when no frames were received on a connection for the duration. Connections that do not answer within the ping timeout are closed.
+func WithHTTP2ReadIdleTimeout(d time.Duration) HTTP2Option {
+	return func(s *http2.Server) error {
+		if d < time.Second {
+			return errors.New("HTTP/2 read idle timeout must be at least one second")
+		}
+		if d > time.Minute*10 {
+			return errors.New("HTTP/2 read idle timeout must not exceed ten minutes")
+		}
+		s.ReadIdleTimeout = d
+		return nil
+	}
+}
+
+// WithHTTP2PingTimeout closes connections that do not answer a health check ping within the duration.
+func WithHTTP2PingTimeout(d time.Duration) HTTP2Option {
+	return func(s *http2.Server) error {
+		if d < time.Second {
+			return errors.New("HTTP/2 ping timeout must be at least one second")
+		}
+		if d > time.Minute {
+			return errors.New("HTTP/2 ping timeout must not exceed one minute")
+		}
+		s.PingTimeout = d
+		return nil
+	}
+}
+
+// WithH2C serves HTTP/2 without TLS, known as h2c, on listeners without TLS, for service meshes that terminate TLS at the sidecar. HTTP/2 limits apply to TLS listeners as well. Idle connection timeout follows [WithIdleTimeout]. On shutdown, HTTP/2 clients receive GOAWAY frames so they finish in-flight streams and reconnect elsewhere.
+func WithH2C(withOptions ...HTTP2Option) Option {
+	return func(o *options) error {
+		if o.HTTP2 != nil {
+			return errors.New("HTTP/2 cleartext option is already set")
+		}
+		h2s := &http2.Server{
+			MaxConcurrentStreams: DefaultHTTP2MaxConcurrentStreams,
+			ReadIdleTimeout:      DefaultHTTP2ReadIdleTimeout,
+			PingTimeout:          DefaultHTTP2PingTimeout,
+		}
+		for _, option := range withOptions {
+			if err := option(h2s); err != nil {
+				return fmt.Errorf("cannot configure HTTP/2: %w", err)
+			}
+		}
+		o.HTTP2 = h2s
+		return nil
+	}
+}
+
+// configureHTTP2 applies HTTP/2 limits to the server and upgrades cleartext connections to h2c. Each server gets its own copy of limits, because [http2.ConfigureServer] attaches connection state to it.
+func configureHTTP2(server *http.Server, l namedListener, limits *http2.Server) error {
+	h2s := &http2.Server{
+		MaxConcurrentStreams: limits.MaxConcurrentStreams,
+		ReadIdleTimeout:      limits.ReadIdleTimeout,
+		PingTimeout:          limits.PingTimeout,
+	}
+	if err := http2.ConfigureServer(server, h2s); err != nil {
+		return fmt.Errorf("cannot configure HTTP/2 for listener %q: %w", l.Name, err)
+	}
+	if l.TLSConfig == nil {
+		server.Handler = h2c.NewHandler(server.Handler, h2s)
+	}
+	return nil
+}
+
+// WithExperimentalHTTP3 serves HTTP/3 over QUIC on the UDP port that matches each TCP listener with TLS. Responses over TCP advertise the HTTP/3 endpoint with the Alt-Svc header, so that browsers can switch to it. QUIC sockets cannot be passed to the new process during a binary upgrade, so this option cannot be combined with [WithUpgradeSignal].
+func WithExperimentalHTTP3() Option {
+	return func(o *options) error {
+		if o.HTTP3 {
+			return errors.New("HTTP/3 option is already set")
+		}
+		o.HTTP3 = true
+		return nil
+	}
+}
+
+func newHTTP3Server(o *options, l namedListener) (*http3.Server, net.PacketConn, error) {
+	address, ok := l.Listener.Addr().(*net.TCPAddr)
+	if !ok {
+		return nil, nil, fmt.Errorf("cannot serve HTTP/3 next to non-TCP listener %q", l.Name)
+	}
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: address.IP, Port: address.Port, Zone: address.Zone})
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot bind HTTP/3 socket for listener %q: %w", l.Name, err)
+	}
+	return &http3.Server{
+		Handler:        l.Handler,
+		TLSConfig:      http3.ConfigureTLSConfig(l.TLSConfig),
+		MaxHeaderBytes: o.MaxHeaderBytes,
+		IdleTimeout:    o.IdleTimeout,
+		Logger:         o.Logger,
+	}, conn, nil
+}
+
+// advertiseHTTP3 sets the Alt-Svc header on every response.
+func advertiseHTTP3(h3 *http3.Server, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = h3.SetQUICHeaders(w.Header())
+		next.ServeHTTP(w, r)
+	})
+}
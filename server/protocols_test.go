@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+)
+
+func serveProtocol(t *testing.T, withOptions ...Option) (stop func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	errChannel := make(chan error)
+	go func() {
+		errChannel <- Run(ctx, append(withOptions,
+			WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(r.Proto))
+			})),
+		)...)
+	}()
+	time.Sleep(time.Millisecond * 50)
+	return func() {
+		cancel()
+		if err := <-errChannel; err != nil {
+			t.Fatal("server shut down with an error:", err)
+		}
+	}
+}
+
+func readProtocol(t *testing.T, client *http.Client, url string) (*http.Response, string) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(b)
+}
+
+func TestH2C(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := serveProtocol(t,
+		WithListener(ln),
+		WithH2C(WithHTTP2MaxConcurrentStreams(10), WithHTTP2ReadIdleTimeout(time.Second*5)),
+	)
+	defer stop()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, address string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, address)
+		},
+	}}
+	if _, proto := readProtocol(t, client, "http://"+ln.Addr().String()); proto != "HTTP/2.0" {
+		t.Fatal("unexpected protocol:", proto)
+	}
+	if _, proto := readProtocol(t, http.DefaultClient, "http://"+ln.Addr().String()); proto != "HTTP/1.1" {
+		t.Fatal("HTTP/1.1 clients are no longer served:", proto)
+	}
+}
+
+func TestExperimentalHTTP3(t *testing.T) {
+	certificate := newTestServerCertificate(t, "localhost")
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := serveProtocol(t,
+		WithListener(ln),
+		WithTLS(certificate.CertificateFile, certificate.KeyFile),
+		WithExperimentalHTTP3(),
+	)
+	defer stop()
+	url := "https://" + ln.Addr().String()
+
+	resp, _ := readProtocol(t, &http.Client{Transport: &http.Transport{
+		TLSClientConfig: newTestClientTLSConfig(certificate),
+	}}, url)
+	if altSvc := resp.Header.Get("Alt-Svc"); !strings.Contains(altSvc, "h3=") {
+		t.Fatalf("HTTP/3 endpoint is not advertised: %q", altSvc)
+	}
+
+	transport := &http3.Transport{TLSClientConfig: newTestClientTLSConfig(certificate)}
+	defer transport.Close()
+	if _, proto := readProtocol(t, &http.Client{Transport: transport}, url); proto != "HTTP/3.0" {
+		t.Fatal("unexpected protocol:", proto)
+	}
+}
+
+func TestExperimentalHTTP3RejectsUpgrades(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	err = Run(
+		context.Background(),
+		WithListener(ln),
+		WithHandler(http.NotFoundHandler()),
+		WithExperimentalHTTP3(),
+		WithUpgradeSignal(os.Interrupt),
+	)
+	if err == nil || !strings.Contains(err.Error(), "HTTP/3") {
+		t.Fatal("HTTP/3 with binary upgrades should be rejected:", err)
+	}
+}
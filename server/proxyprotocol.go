@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+var (
+	proxyProtocolV1Prefix  = []byte("PROXY ")
+	proxyProtocolSignature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// readProxyProtocolHeader consumes a PROXY protocol version 1 or 2 header and returns the original client address. Returns nil address for health check connections made by the proxy itself, which carry LOCAL or UNKNOWN headers. See <https://www.haproxy.org/download/2.9/doc/proxy-protocol.txt>.
+func readProxyProtocolHeader(r *bufio.Reader) (net.Addr, error) {
+	peek, err := r.Peek(len(proxyProtocolV1Prefix))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read PROXY protocol header: %w", err)
+	}
+	if bytes.Equal(peek, proxyProtocolV1Prefix) {
+		return readProxyProtocolV1(r)
+	}
+	if peek, err = r.Peek(len(proxyProtocolSignature)); err != nil || !bytes.Equal(peek, proxyProtocolSignature) {
+		return nil, errors.New("connection did not start with a PROXY protocol header")
+	}
+	return readProxyProtocolV2(r)
+}
+
+func readProxyProtocolV1(r *bufio.Reader) (net.Addr, error) {
+	const maximumLength = 107
+	line := make([]byte, 0, maximumLength)
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("cannot read PROXY protocol header: %w", err)
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+		if len(line) == maximumLength {
+			return nil, errors.New("PROXY protocol header is too long")
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errors.New("PROXY protocol header does not end with CRLF")
+	}
+
+	fields := strings.Split(string(line[len(proxyProtocolV1Prefix):len(line)-2]), " ")
+	switch {
+	case len(fields) > 0 && fields[0] == "UNKNOWN":
+		return nil, nil
+	case len(fields) != 5 || (fields[0] != "TCP4" && fields[0] != "TCP6"):
+		return nil, fmt.Errorf("invalid PROXY protocol header: %q", line)
+	}
+	address, err := netip.ParseAddr(fields[1])
+	if err != nil || address.Is4() != (fields[0] == "TCP4") {
+		return nil, fmt.Errorf("invalid PROXY protocol source address: %q", fields[1])
+	}
+	port, err := strconv.ParseUint(fields[3], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY protocol source port: %q", fields[3])
+	}
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(address, uint16(port))), nil
+}
+
+func readProxyProtocolV2(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("cannot read PROXY protocol header: %w", err)
+	}
+	if version := header[12] >> 4; version != 2 {
+		return nil, fmt.Errorf("unsupported PROXY protocol version %d", version)
+	}
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("cannot read PROXY protocol addresses: %w", err)
+	}
+
+	switch command := header[12] & 0x0f; command {
+	case 0x0: // LOCAL
+		return nil, nil
+	case 0x1: // PROXY
+	default:
+		return nil, fmt.Errorf("unsupported PROXY protocol command %d", command)
+	}
+	switch family := header[13]; family {
+	case 0x11, 0x12: // TCP or UDP over IPv4
+		if len(payload) < 12 {
+			return nil, errors.New("PROXY protocol IPv4 addresses are truncated")
+		}
+		address := netip.AddrFrom4([4]byte(payload[0:4]))
+		return net.TCPAddrFromAddrPort(netip.AddrPortFrom(address, binary.BigEndian.Uint16(payload[8:10]))), nil
+	case 0x21, 0x22: // TCP or UDP over IPv6
+		if len(payload) < 36 {
+			return nil, errors.New("PROXY protocol IPv6 addresses are truncated")
+		}
+		address := netip.AddrFrom16([16]byte(payload[0:16]))
+		return net.TCPAddrFromAddrPort(netip.AddrPortFrom(address, binary.BigEndian.Uint16(payload[32:34]))), nil
+	default: // UNSPEC and unix sockets
+		return nil, nil
+	}
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dkotik/oakhttp"
+)
+
+const (
+	RedirectListenerName = "redirect"
+
+	// ACMEChallengePathPrefix is reserved for HTTP-01 challenges, see RFC 8555 section 8.3.
+	ACMEChallengePathPrefix = "/.well-known/acme-challenge/"
+)
+
+type redirectOptions struct {
+	Listener   net.Listener
+	HTTPSPort  uint32
+	ACME       http.Handler
+	AllowHosts []string
+}
+
+type RedirectOption func(*redirectOptions) error
+
+// WithRedirectListener accepts plain HTTP requests on the listener instead of port 80.
+func WithRedirectListener(l net.Listener) RedirectOption {
+	return func(o *redirectOptions) error {
+		if o.Listener != nil {
+			return errors.New("redirect listener is already set")
+		}
+		if l == nil {
+			return errors.New("cannot use a <nil> network listener")
+		}
+		o.Listener = l
+		return nil
+	}
+}
+
+// WithRedirectHTTPSPort redirects to a non-standard HTTPS port.
+func WithRedirectHTTPSPort(port uint32) RedirectOption {
+	return func(o *redirectOptions) error {
+		if o.HTTPSPort != 0 {
+			return errors.New("redirect HTTPS port is already set")
+		}
+		if port < 1 || port > 65535 {
+			return errors.New("HTTPS port must be between 1 and 65535")
+		}
+		o.HTTPSPort = port
+		return nil
+	}
+}
+
+// WithACMEChallengeHandler serves requests under [ACMEChallengePathPrefix] to the handler instead of redirecting them. The handler may be provided by any ACME client, such as [golang.org/x/crypto/acme/autocert.Manager.HTTPHandler].
+func WithACMEChallengeHandler(h http.Handler) RedirectOption {
+	return func(o *redirectOptions) error {
+		if o.ACME != nil {
+			return errors.New("ACME challenge handler is already set")
+		}
+		if h == nil {
+			return errors.New("cannot use a <nil> ACME challenge handler")
+		}
+		o.ACME = h
+		return nil
+	}
+}
+
+// WithRedirectHosts restricts redirects to known host names. Requests for other hosts are rejected with [http.StatusMisdirectedRequest].
+func WithRedirectHosts(hosts ...string) RedirectOption {
+	return func(o *redirectOptions) error {
+		for _, host := range hosts {
+			if host == "" {
+				return errors.New("cannot allow an empty host")
+			}
+			o.AllowHosts = append(o.AllowHosts, strings.ToLower(host))
+		}
+		return nil
+	}
+}
+
+// NewHTTPSRedirectHandler redirects plain HTTP requests to the same host and path over HTTPS. Safe methods are redirected permanently with [http.StatusMovedPermanently], others with [http.StatusPermanentRedirect] to preserve the method and body.
+func NewHTTPSRedirectHandler(withOptions ...RedirectOption) (http.Handler, error) {
+	o := &redirectOptions{}
+	for _, option := range withOptions {
+		if err := option(o); err != nil {
+			return nil, fmt.Errorf("cannot create HTTPS redirect handler: %w", err)
+		}
+	}
+	if o.Listener != nil {
+		return nil, errors.New("cannot create HTTPS redirect handler: listener is only used by server option")
+	}
+	return newHTTPSRedirectHandler(o), nil
+}
+
+func newHTTPSRedirectHandler(o *redirectOptions) http.Handler {
+	port := ""
+	if o.HTTPSPort != 0 && o.HTTPSPort != 443 {
+		port = strconv.FormatUint(uint64(o.HTTPSPort), 10)
+	}
+	acme := o.ACME
+	if acme == nil {
+		acme = http.NotFoundHandler()
+	}
+	allowHosts := o.AllowHosts
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, ACMEChallengePathPrefix) {
+			acme.ServeHTTP(w, r)
+			return
+		}
+
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		} else {
+			host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+		}
+		host = strings.ToLower(strings.TrimSuffix(host, "."))
+		if host == "" {
+			http.Error(w, "Host header is required", http.StatusBadRequest)
+			return
+		}
+		if len(allowHosts) > 0 && !slices.Contains(allowHosts, host) {
+			http.Error(w, http.StatusText(http.StatusMisdirectedRequest), http.StatusMisdirectedRequest)
+			return
+		}
+		if port != "" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]" // IPv6 literal
+		}
+
+		statusCode := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			statusCode = http.StatusMovedPermanently
+		}
+		w.Header().Set("Connection", "close")
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), statusCode)
+	})
+}
+
+// WithHTTPSRedirect starts a companion listener on port 80 that redirects plain HTTP requests to HTTPS and answers ACME HTTP-01 challenges. It shares the server life cycle and is named [RedirectListenerName].
+//
+//	err := server.Run(
+//	  ctx,
+//	  server.WithHandler(application),
+//	  server.WithTLS("cert.pem", "key.pem"),
+//	  server.WithHTTPSRedirect(server.WithACMEChallengeHandler(acmeManager.HTTPHandler(nil))),
+//	  server.WithStrictTransportSecurity(oakhttp.WithStrictTransportSecurityIncludeSubDomains()),
+//	)
+func WithHTTPSRedirect(withOptions ...RedirectOption) Option {
+	return func(o *options) (err error) {
+		redirect := &redirectOptions{}
+		for _, option := range withOptions {
+			if err = option(redirect); err != nil {
+				return fmt.Errorf("cannot configure HTTPS redirect: %w", err)
+			}
+		}
+		if redirect.Listener == nil {
+			if redirect.Listener, err = listen(RedirectListenerName, "tcp", ":80"); err != nil {
+				return fmt.Errorf("cannot bind HTTPS redirect listener: %w", err)
+			}
+		}
+		return WithNamedListener(
+			RedirectListenerName,
+			redirect.Listener,
+			WithListenerHandler(newHTTPSRedirectHandler(redirect)),
+		)(o)
+	}
+}
+
+// WithStrictTransportSecurity adds the Strict-Transport-Security header to responses of every TLS listener.
+func WithStrictTransportSecurity(withOptions ...oakhttp.StrictTransportSecurityOption) Option {
+	return func(o *options) error {
+		if o.StrictTransportSecurity != nil {
+			return errors.New("strict transport security is already set")
+		}
+		mw, err := oakhttp.NewStrictTransportSecurityMiddleware(withOptions...)
+		if err != nil {
+			return err
+		}
+		o.StrictTransportSecurity = mw
+		return nil
+	}
+}
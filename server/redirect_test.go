@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dkotik/oakhttp"
+)
+
+func TestHTTPSRedirectHandler(t *testing.T) {
+	h, err := NewHTTPSRedirectHandler(
+		WithRedirectHTTPSPort(8443),
+		WithACMEChallengeHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("challenge"))
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Method     string
+		Target     string
+		Host       string
+		StatusCode int
+		Location   string
+	}{
+		{http.MethodGet, "/path?query=1", "example.com", http.StatusMovedPermanently, "https://example.com:8443/path?query=1"},
+		{http.MethodPost, "/form", "Example.com:80", http.StatusPermanentRedirect, "https://example.com:8443/form"},
+		{http.MethodGet, "/", "[::1]", http.StatusMovedPermanently, "https://[::1]:8443/"},
+		{http.MethodGet, ACMEChallengePathPrefix + "token", "example.com", http.StatusOK, ""},
+		{http.MethodGet, "/", "", http.StatusBadRequest, ""},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(c.Method, c.Target, nil)
+		r.Host = c.Host
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != c.StatusCode {
+			t.Errorf("%s %s: unexpected status code %d", c.Method, c.Target, w.Code)
+		}
+		if location := w.Header().Get("Location"); location != c.Location {
+			t.Errorf("%s %s: unexpected location %q", c.Method, c.Target, location)
+		}
+	}
+
+	restricted, err := NewHTTPSRedirectHandler(WithRedirectHosts("example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Host = "attacker.com"
+	w := httptest.NewRecorder()
+	restricted.ServeHTTP(w, r)
+	if w.Code != http.StatusMisdirectedRequest {
+		t.Fatal("unknown host was redirected")
+	}
+}
+
+func TestHTTPSRedirectCompanion(t *testing.T) {
+	certificate := newTestServerCertificate(t, "localhost")
+	secure, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errChannel := make(chan error)
+	go func() {
+		errChannel <- Run(
+			ctx,
+			WithListener(secure),
+			WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})),
+			WithTLS(certificate.CertificateFile, certificate.KeyFile),
+			WithHTTPSRedirect(WithRedirectListener(plain)),
+			WithStrictTransportSecurity(),
+		)
+	}()
+	time.Sleep(time.Millisecond * 50)
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get("http://" + plain.Addr().String() + "/path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusMovedPermanently {
+		t.Fatal("plain request was not redirected:", resp.StatusCode)
+	}
+	if resp.Header.Get(oakhttp.StrictTransportSecurityHeader) != "" {
+		t.Fatal("plain listener should not set strict transport security")
+	}
+
+	client.Transport = &http.Transport{TLSClientConfig: newTestClientTLSConfig(certificate)}
+	resp, err = client.Get("https://" + secure.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.Header.Get(oakhttp.StrictTransportSecurityHeader) == "" {
+		t.Fatal("TLS listener did not set strict transport security")
+	}
+
+	cancel()
+	if err = <-errChannel; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHTTPSRedirectKeepsDefaultListener(t *testing.T) {
+	certificate := newTestServerCertificate(t, "localhost")
+	secure := newInheritedTestListener(t, DefaultListenerName)
+	plain := newInheritedTestListener(t, RedirectListenerName)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errChannel := make(chan error)
+	go func() {
+		errChannel <- Run(
+			ctx,
+			WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})),
+			WithTLS(certificate.CertificateFile, certificate.KeyFile),
+			WithHTTPSRedirect(),
+		)
+	}()
+	time.Sleep(time.Millisecond * 50)
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: newTestClientTLSConfig(certificate)},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get("http://" + plain.Addr().String() + "/path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if location := resp.Header.Get("Location"); location != "https://127.0.0.1/path" {
+		t.Fatal("unexpected redirect location:", location)
+	}
+
+	resp, err = client.Get("https://" + secure.Addr().String())
+	if err != nil {
+		t.Fatal("HTTPS listener is not serving:", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatal("unexpected status code:", resp.StatusCode)
+	}
+
+	cancel()
+	if err = <-errChannel; err != nil {
+		t.Fatal(err)
+	}
+}
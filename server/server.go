@@ -5,6 +5,21 @@ Package server provides a standard library [http.Server] with conventional produ
 
 	err := server.Run(context.Background())
 
+# Graceful Shutdown
+
+When the context is cancelled or the process receives an interrupt or termination signal, [Readiness] turns false, the server keeps serving for [WithReadinessDelay] so that load balancers stop routing traffic, then drains in-flight requests within [WithShutdownTimeout], and finally runs [WithShutdownHook] hooks.
+
+	readiness := &server.Readiness{}
+	mux.Handle("GET /readyz", readiness)
+	err := server.Run(
+	  context.Background(),
+	  server.WithHandler(mux),
+	  server.WithReadiness(readiness),
+	  server.WithReadinessDelay(time.Second*5),
+	  server.WithShutdownTimeout(time.Second*30),
+	  server.WithShutdownCloser(database),
+	)
+
 # NGrok Usage
 
 server is easy to use with <https://ngrok.com> tunnel, which exposes your local server to the world. Use with caution. You should be fairly confident that your code is secure and will not leak data from your system or damage it.
@@ -40,6 +55,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -67,64 +83,187 @@ func Run(ctx context.Context, withOptions ...Option) (err error) {
 		WithDefaultOptions(),
 		WithDefaultTraceIDGenerator(),
 		func(o *options) error { // validate
-			if o.Listener == nil {
+			if o.Listener == nil && len(o.Listeners) == 0 {
 				return errors.New("cannot start a server without a network listener")
 			}
-			if o.Handler == nil {
-				return errors.New("service handler is nil")
+			if o.HTTP3 && o.UpgradeSignal != nil {
+				// the upgraded process could not bind UDP ports held by this one
+				return errors.New("HTTP/3 cannot be combined with binary upgrades, because QUIC sockets are not passed to the upgraded process")
+			}
+			for _, l := range o.listeners() {
+				if l.Handler == nil {
+					return fmt.Errorf("service handler for listener %q is nil", l.Name)
+				}
 			}
 			return nil
 		},
 	) {
 		if err = option(o); err != nil {
+			o.closeListeners()
 			return fmt.Errorf("cannot create an Oak server: %w", err)
 		}
 	}
-	ln := o.Listener
+	listeners := o.listeners()
 	defer func() {
-		if err := ln.Close(); err != nil {
-			o.Logger.Error("failed closing network listener", slog.Any("error", err))
+		for _, l := range listeners {
+			// [http.Server.Shutdown] closes the listener after serving
+			if err := l.Listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+				o.Logger.Error("failed closing network listener", slog.String("listener", l.Name), slog.Any("error", err))
+			}
 		}
 	}()
 
-	contextFactory := o.ContextFactory
 	logger := o.Logger
-	server := &http.Server{
-		// Addr:              o.Address,
-		ReadTimeout:       o.ReadTimeout,
-		ReadHeaderTimeout: o.ReadHeaderTimeout,
-		WriteTimeout:      o.WriteTimeout,
-		IdleTimeout:       o.IdleTimeout,
-		MaxHeaderBytes:    o.MaxHeaderBytes,
-		Handler:           o.Handler,
-		BaseContext:       contextFactory,
-		ErrorLog:          oakhttp.NewSlogAdaptor(logger, slog.LevelDebug),
-		// TLSConfig *tls.Config // TODO: use Filippo's defaults?
+	var connState func(net.Conn, http.ConnState)
+	if o.Metrics != nil {
+		connState = newConnectionMetrics(o.Metrics).ConnState
 	}
+	type service struct {
+		Name   string
+		Server gracefulServer
+		Serve  func() error
+	}
+	services := make([]service, 0, len(listeners))
+	var packetConns []net.PacketConn
+	defer func() {
+		for _, conn := range packetConns {
+			_ = conn.Close()
+		}
+	}()
+	for _, l := range listeners {
+		handler := l.Handler
+		if o.HTTP3 && l.TLSConfig != nil {
+			h3, conn, err := newHTTP3Server(o, l)
+			if err != nil {
+				return fmt.Errorf("cannot create an Oak server: %w", err)
+			}
+			packetConns = append(packetConns, conn)
+			handler = advertiseHTTP3(h3, handler)
+			services = append(services, service{
+				Name:   l.Name + "/h3",
+				Server: h3,
+				Serve:  func() error { return h3.Serve(conn) },
+			})
+		}
 
-	go func(ctx context.Context, logger *slog.Logger) {
-		<-ctx.Done()
-		timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		server := &http.Server{
+			// Addr:              o.Address,
+			ReadTimeout:       o.ReadTimeout,
+			ReadHeaderTimeout: o.ReadHeaderTimeout,
+			WriteTimeout:      o.WriteTimeout,
+			IdleTimeout:       o.IdleTimeout,
+			MaxHeaderBytes:    o.MaxHeaderBytes,
+			Handler:           handler,
+			BaseContext:       o.ContextFactory,
+			ConnState:         connState,
+			ErrorLog:          oakhttp.NewSlogAdaptor(logger.With(slog.String("listener", l.Name)), slog.LevelDebug),
+			TLSConfig:         l.TLSConfig,
+		}
+		if o.HTTP2 != nil {
+			if err = configureHTTP2(server, l, o.HTTP2); err != nil {
+				return fmt.Errorf("cannot create an Oak server: %w", err)
+			}
+		}
+		ln := l.Listener // unwrapped listeners are handed off on upgrade
+		if o.ConnectionLimiter != nil {
+			ln = o.ConnectionLimiter.Wrap(ln, logger.With(slog.String("listener", l.Name)))
+		}
+		services = append(services, service{
+			Name:   l.Name,
+			Server: server,
+			Serve: func() error {
+				if l.TLSConfig != nil {
+					return server.ServeTLS(ln, "", "")
+				}
+				return server.Serve(ln)
+			},
+		})
+	}
 
-		// more: https://dev.to/mokiat/proper-http-shutdown-in-go-3fji
-		if err := server.Shutdown(timeoutCtx); err != nil {
-			logger.Error("error shutting down OakHTTP server", slog.Any("error", err))
+	servers := make([]gracefulServer, len(services))
+	served := make(chan error, len(services))
+	for i, s := range services {
+		servers[i] = s.Server
+		go func(s service) {
+			err := s.Serve()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				err = fmt.Errorf("listener %q failed: %w", s.Name, err)
+			}
+			served <- err
+		}(s)
+	}
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	watched := make(map[*CertificateManager]struct{})
+	for _, l := range listeners {
+		if l.CertificateManager == nil {
+			continue
+		}
+		if _, ok := watched[l.CertificateManager]; !ok {
+			watched[l.CertificateManager] = struct{}{}
+			go l.CertificateManager.Watch(watchCtx)
 		}
-	}(ctx, logger)
-
-	if o.TLSCertificateFile != "" {
-		// err = server.ListenAndServeTLS(o.TLSCertificateFile, o.TLSKeyFile)
-		err = server.ServeTLS(ln, o.TLSCertificateFile, o.TLSKeyFile)
-	} else {
-		// if strings.HasSuffix(o.Address, ":443") || strings.HasSuffix(o.Address, ":8443") {
-		// 	return errors.New("must not expose a TLS server without its certificate file set")
-		// }
-		err = server.Serve(ln)
+	}
+	o.Readiness.SetReady(true)
+	if notifyErr := notifyUpgradeParent(); notifyErr != nil {
+		logger.Error("failed to report readiness to parent process", slog.Any("error", notifyErr))
+	}
+
+	var upgradeSignals chan os.Signal
+	if o.UpgradeSignal != nil {
+		upgradeSignals = make(chan os.Signal, 1)
+		signal.Notify(upgradeSignals, o.UpgradeSignal)
+		defer signal.Stop(upgradeSignals)
 	}
 
-	if err != nil {
-		logger.Error("OakHTTP server shutdown", slog.Any("reason", err)) // handle
+	running := len(servers)
+wait:
+	for {
+		select {
+		case err = <-served:
+			running--
+			o.Readiness.SetReady(false)
+			err = fmt.Errorf("OakHTTP server stopped unexpectedly: %w", err)
+			logger.Error("OakHTTP server shutdown", slog.Any("reason", err))
+			break wait
+		case <-upgradeSignals:
+			if upgradeErr := upgrade(ctx, listeners, logger); upgradeErr != nil {
+				logger.Error("OakHTTP server upgrade failed", slog.Any("error", upgradeErr))
+				continue
+			}
+			// the upgraded process accepts connections from the same sockets, so there is no need to wait for load balancers
+			o.Readiness.SetReady(false)
+			logger.Info("OakHTTP server upgraded, shutting down",
+				slog.Duration("timeout", o.ShutdownTimeout),
+			)
+			break wait
+		case <-ctx.Done():
+			o.Readiness.SetReady(false)
+			logger.Info("OakHTTP server shutting down",
+				slog.Any("reason", context.Cause(ctx)),
+				slog.Duration("readiness_delay", o.ReadinessDelay),
+				slog.Duration("timeout", o.ShutdownTimeout),
+			)
+			if o.ReadinessDelay > 0 {
+				time.Sleep(o.ReadinessDelay)
+			}
+			break wait
+		}
+	}
+
+	// more: https://dev.to/mokiat/proper-http-shutdown-in-go-3fji
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), o.ShutdownTimeout)
+	defer cancel()
+	if drainErr := shutdown(shutdownCtx, servers); drainErr != nil {
+		err = errors.Join(err, fmt.Errorf("cannot drain OakHTTP server: %w", drainErr))
+	}
+	for ; running > 0; running-- {
+		if serveErr := <-served; serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			err = errors.Join(err, serveErr)
+		}
+	}
+	if hookErr := runShutdownHooks(shutdownCtx, o.ShutdownHooks); hookErr != nil {
+		err = errors.Join(err, fmt.Errorf("shutdown hook failed: %w", hookErr))
 	}
-	return nil
+	return err
 }
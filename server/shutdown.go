@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultShutdownTimeout = time.Second * 5
+	MaximumShutdownTimeout = time.Minute * 10
+)
+
+// ShutdownHook releases resources after the server stopped accepting requests and the in-flight requests were drained or the drain timeout expired. Use it for closing stores and stopping background workers.
+type ShutdownHook func(context.Context) error
+
+// Readiness reports whether the server is accepting traffic. It turns ready when the server starts serving and not ready as soon as shutdown begins, before in-flight requests are drained, so that load balancers stop routing new traffic. Mount it as a readiness probe:
+//
+//	readiness := &server.Readiness{}
+//	mux.Handle("GET /readyz", readiness)
+type Readiness struct {
+	ready atomic.Bool
+}
+
+func (r *Readiness) IsReady() bool {
+	return r.ready.Load()
+}
+
+func (r *Readiness) SetReady(ready bool) {
+	r.ready.Store(ready)
+}
+
+// ServeHTTP responds with [http.StatusOK] when ready and [http.StatusServiceUnavailable] otherwise.
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if r.IsReady() {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ready\n"))
+		return
+	}
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_, _ = w.Write([]byte("not ready\n"))
+}
+
+// WithShutdownTimeout limits how long the server waits for in-flight requests to complete before closing remaining connections. The same deadline applies to shutdown hooks.
+func WithShutdownTimeout(t time.Duration) Option {
+	return func(o *options) error {
+		if o.ShutdownTimeout != 0 {
+			return errors.New("shutdown timeout is already set")
+		}
+		if t < time.Millisecond*100 {
+			return errors.New("cannot set shutdown timeout lower than 100ms")
+		}
+		if t > MaximumShutdownTimeout {
+			return errors.New("cannot set shutdown timeout above ten minutes")
+		}
+		o.ShutdownTimeout = t
+		return nil
+	}
+}
+
+// WithReadiness flips the readiness state during the server life cycle. Pair with [WithReadinessDelay] to give load balancers time to notice before draining begins.
+func WithReadiness(r *Readiness) Option {
+	return func(o *options) error {
+		if o.Readiness != nil {
+			return errors.New("readiness state is already set")
+		}
+		if r == nil {
+			return errors.New("cannot use a <nil> readiness state")
+		}
+		o.Readiness = r
+		return nil
+	}
+}
+
+// WithReadinessDelay keeps serving requests for the given duration after readiness turns false and before draining begins. The delay is usually set slightly above the load balancer health check interval.
+func WithReadinessDelay(d time.Duration) Option {
+	return func(o *options) error {
+		if o.ReadinessDelay != 0 {
+			return errors.New("readiness delay is already set")
+		}
+		if d <= 0 {
+			return errors.New("readiness delay must be positive")
+		}
+		if d > time.Minute {
+			return errors.New("cannot set readiness delay above one minute")
+		}
+		o.ReadinessDelay = d
+		return nil
+	}
+}
+
+// WithShutdownHook registers a hook that runs after the server drains. Hooks run in reverse order of registration, so resources set up first are released last. Hook errors are returned from [Run].
+func WithShutdownHook(hook ShutdownHook) Option {
+	return func(o *options) error {
+		if hook == nil {
+			return errors.New("cannot use a <nil> shutdown hook")
+		}
+		o.ShutdownHooks = append(o.ShutdownHooks, hook)
+		return nil
+	}
+}
+
+// WithShutdownCloser registers a hook that calls Close, which is common for stores and workers.
+func WithShutdownCloser(closer interface{ Close() error }) Option {
+	return func(o *options) error {
+		if closer == nil {
+			return errors.New("cannot use a <nil> closer")
+		}
+		return WithShutdownHook(func(_ context.Context) error {
+			return closer.Close()
+		})(o)
+	}
+}
+
+// shutdown drains all servers concurrently. Servers that fail to drain before the deadline are closed.
+func shutdown(ctx context.Context, servers []gracefulServer) error {
+	errs := make(chan error, len(servers))
+	for _, server := range servers {
+		go func(server gracefulServer) {
+			if err := server.Shutdown(ctx); err != nil {
+				errs <- errors.Join(err, server.Close())
+				return
+			}
+			errs <- nil
+		}(server)
+	}
+	var err error
+	for range servers {
+		err = errors.Join(err, <-errs)
+	}
+	return err
+}
+
+func runShutdownHooks(ctx context.Context, hooks []ShutdownHook) (err error) {
+	for i := len(hooks) - 1; i >= 0; i-- {
+		err = errors.Join(err, hooks[i](ctx))
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGracefulShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	readiness := &Readiness{}
+	started := make(chan struct{})
+	var hooks []string
+	errChannel := make(chan error)
+	go func() {
+		errChannel <- Run(
+			ctx,
+			WithListener(ln),
+			WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				time.Sleep(time.Millisecond * 200)
+				w.WriteHeader(http.StatusNoContent)
+			})),
+			WithReadiness(readiness),
+			WithReadinessDelay(time.Millisecond*50),
+			WithShutdownTimeout(time.Second),
+			WithShutdownHook(func(context.Context) error {
+				hooks = append(hooks, "first")
+				return nil
+			}),
+			WithShutdownHook(func(context.Context) error {
+				hooks = append(hooks, "second")
+				return nil
+			}),
+		)
+	}()
+
+	response := make(chan *http.Response)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			t.Error(err)
+		}
+		response <- resp
+	}()
+	<-started
+	if !readiness.IsReady() {
+		t.Fatal("server is serving, but not ready")
+	}
+	cancel()
+	time.Sleep(time.Millisecond * 20)
+	if readiness.IsReady() {
+		t.Fatal("readiness did not flip before draining")
+	}
+
+	if resp := <-response; resp == nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatal("in-flight request was not drained")
+	} else {
+		_ = resp.Body.Close()
+	}
+	if err = <-errChannel; err != nil {
+		t.Fatal("graceful shutdown failed:", err)
+	}
+	if len(hooks) != 2 || hooks[0] != "second" || hooks[1] != "first" {
+		t.Fatal("shutdown hooks did not run in reverse order:", hooks)
+	}
+}
+
+func TestShutdownErrors(t *testing.T) {
+	t.Run("serve failure", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = ln.Close()
+
+		hookCalled := false
+		err = Run(
+			context.Background(),
+			WithListener(ln),
+			WithHandler(http.NotFoundHandler()),
+			WithShutdownHook(func(context.Context) error {
+				hookCalled = true
+				return nil
+			}),
+		)
+		if !errors.Is(err, net.ErrClosed) {
+			t.Fatal("serve error was not returned:", err)
+		}
+		if !hookCalled {
+			t.Fatal("shutdown hook was not called")
+		}
+	})
+
+	t.Run("hook failure", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+
+		hookErr := errors.New("hook error")
+		err = Run(
+			ctx,
+			WithListener(ln),
+			WithHandler(http.NotFoundHandler()),
+			WithShutdownHook(func(context.Context) error {
+				return hookErr
+			}),
+		)
+		if !errors.Is(err, hookErr) {
+			t.Fatal("hook error was not returned:", err)
+		}
+	})
+}
@@ -3,8 +3,6 @@ package server
 import (
 	"errors"
 	"fmt"
-
-	"github.com/coreos/go-systemd/activation"
 )
 
 // WithSystemDSocketActivationPort binds the server to a listener specified by systemd configuration. This will preserve TCP connections during service restarts.
@@ -44,42 +42,64 @@ func WithFirstSystemDSocketActivationSocket() Option {
 		if o.Listener != nil {
 			return errors.New("server address is already set")
 		}
-		listeners, err := activation.Listeners()
-		if err != nil {
-			return fmt.Errorf("could not access systemd network listeners: %w", err)
-		}
-		if len(listeners) == 0 {
+		ln, ok := inherited().Take("")
+		if !ok {
+			if err := inherited().err; err != nil {
+				return fmt.Errorf("could not access systemd network listeners: %w", err)
+			}
 			return errors.New("systemd service has no associated network listeners")
 		}
-
-		o.Listener = listeners[0]
+		o.Listener = ln
 		return nil
 	}
 }
 
-// // TODO: below seems to produce a list of listeners by file name, probably not useful differentiation.
-// func WithSystemDSocketActivationSocket(name string) Option {
-// 	return func(o *options) error {
-// 		if o.Listener != nil {
-// 			return errors.New("server address is already set")
-// 		}
-// 		if name == "" {
-// 			return errors.New("systemd socket name is required")
-// 		}
-// 		listeners, err := activation.ListenersWithNames()
-// 		if err != nil {
-// 			return fmt.Errorf("could not access systemd network listeners: %w", err)
-// 		}
-// 		if len(listeners) == 0 {
-// 			return errors.New("systemd service has no associated network listeners")
-// 		}
+// WithSystemDSocketActivationSocket serves systemd sockets named by the FileDescriptorName setting using [WithNamedListener]. When several sockets share the name, the ones after the first are named with a numeric suffix, like "https#2".
+//
+//	// /lib/systemd/system/myapp-https.socket
+//	[Socket]
+//	ListenStream       = 443
+//	FileDescriptorName = https
+//	Service            = myapp.service
+//
+//	// /lib/systemd/system/myapp-admin.socket
+//	[Socket]
+//	ListenStream       = /run/myapp/admin.sock
+//	FileDescriptorName = admin
+//	Service            = myapp.service
 //
-// 		ln, ok := listeners[name]
-// 		if !ok {
-// 			return fmt.Errorf("socket named %q is not associated with systemd service", name)
-// 		}
+//	err := server.Run(
+//	  ctx,
+//	  server.WithHandler(application),
+//	  server.WithSystemDSocketActivationSocket("https",
+//	    server.WithListenerTLS("cert.pem", "key.pem")),
+//	  server.WithSystemDSocketActivationSocket("admin",
+//	    server.WithListenerHandler(admin)),
+//	)
 //
-// 		o.Listener = ln[0]
-// 		return nil
-// 	}
-// }
+// Socket activation variables are read once per process, so that each option can look up its own sockets.
+func WithSystemDSocketActivationSocket(name string, withOptions ...ListenerOption) Option {
+	return func(o *options) error {
+		if name == "" {
+			return errors.New("systemd socket name is required")
+		}
+		named := inherited().TakeAll(name)
+		if len(named) == 0 {
+			if err := inherited().err; err != nil {
+				return fmt.Errorf("could not access systemd network listeners: %w", err)
+			}
+			return fmt.Errorf("socket named %q is not associated with systemd service", name)
+		}
+		for i, ln := range named {
+			var err error
+			listenerName := name
+			if i > 0 {
+				listenerName = fmt.Sprintf("%s#%d", name, i+1)
+			}
+			if err = WithNamedListener(listenerName, ln, withOptions...)(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
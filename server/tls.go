@@ -0,0 +1,281 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const DefaultCertificateReloadInterval = time.Minute
+
+// NewTLSConfig returns a hardened TLS configuration that selects certificates from the manager by server name indication. It requires TLS 1.2 or higher, prefers modern elliptic curves, and restricts TLS 1.2 to forward-secret AEAD cipher suites. TLS 1.3 suites are not configurable and are always secure.
+func NewTLSConfig(m *CertificateManager, withOptions ...TLSOption) (*tls.Config, error) {
+	if m == nil {
+		return nil, errors.New("cannot use a <nil> certificate manager")
+	}
+	config := &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		GetCertificate: m.GetCertificate,
+	}
+	for _, option := range withOptions {
+		if err := option(config); err != nil {
+			return nil, fmt.Errorf("cannot create TLS configuration: %w", err)
+		}
+	}
+	return config, nil
+}
+
+// TLSOption adjusts the TLS configuration created by [NewTLSConfig].
+type TLSOption func(*tls.Config) error
+
+// WithClientCertificateAuthority requires clients to present certificates signed by one of the authorities in the PEM bundle file. Use for mutual TLS between services.
+func WithClientCertificateAuthority(bundleFile string) TLSOption {
+	return func(c *tls.Config) error {
+		if c.ClientCAs != nil {
+			return errors.New("client certificate authority is already set")
+		}
+		bundle, err := os.ReadFile(bundleFile)
+		if err != nil {
+			return fmt.Errorf("cannot read client certificate authority bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bundle) {
+			return fmt.Errorf("client certificate authority bundle %q contains no certificates", bundleFile)
+		}
+		c.ClientCAs = pool
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+		return nil
+	}
+}
+
+type certificateKeyPair struct {
+	CertificateFile string
+	KeyFile         string
+}
+
+type certificateManagerOptions struct {
+	Pairs          []certificateKeyPair
+	ReloadInterval time.Duration
+	Logger         *slog.Logger
+}
+
+type CertificateManagerOption func(*certificateManagerOptions) error
+
+// WithCertificateKeyPair adds a certificate with its private key. The certificate is served for every DNS name it covers. The first pair is served when no name matches.
+func WithCertificateKeyPair(certificateFile, keyFile string) CertificateManagerOption {
+	return func(o *certificateManagerOptions) error {
+		if certificateFile == "" || keyFile == "" {
+			return errors.New("TLS certificate and key files are required")
+		}
+		o.Pairs = append(o.Pairs, certificateKeyPair{
+			CertificateFile: certificateFile,
+			KeyFile:         keyFile,
+		})
+		return nil
+	}
+}
+
+// WithCertificateReloadInterval sets how often certificate files are checked for changes by [CertificateManager.Watch].
+func WithCertificateReloadInterval(t time.Duration) CertificateManagerOption {
+	return func(o *certificateManagerOptions) error {
+		if o.ReloadInterval != 0 {
+			return errors.New("certificate reload interval is already set")
+		}
+		if t < time.Millisecond*10 {
+			return errors.New("cannot set certificate reload interval lower than 10ms")
+		}
+		o.ReloadInterval = t
+		return nil
+	}
+}
+
+func WithCertificateLogger(logger *slog.Logger) CertificateManagerOption {
+	return func(o *certificateManagerOptions) error {
+		if logger == nil {
+			return errors.New("cannot use a <nil> structured logger")
+		}
+		if o.Logger != nil {
+			return errors.New("certificate logger is already set")
+		}
+		o.Logger = logger
+		return nil
+	}
+}
+
+type fileVersion struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// CertificateManager serves certificates by server name and replaces them without restarting the process when the files change or the process receives SIGHUP.
+type CertificateManager struct {
+	pairs          []certificateKeyPair
+	reloadInterval time.Duration
+	logger         *slog.Logger
+
+	mu           sync.RWMutex
+	versions     []fileVersion
+	certificates []*tls.Certificate
+	byName       map[string]*tls.Certificate
+}
+
+func NewCertificateManager(withOptions ...CertificateManagerOption) (*CertificateManager, error) {
+	o := &certificateManagerOptions{}
+	for _, option := range append(
+		withOptions,
+		func(o *certificateManagerOptions) error { // default and validate
+			if len(o.Pairs) == 0 {
+				return errors.New("at least one certificate key pair is required")
+			}
+			if o.ReloadInterval == 0 {
+				o.ReloadInterval = DefaultCertificateReloadInterval
+			}
+			if o.Logger == nil {
+				o.Logger = slog.Default()
+			}
+			return nil
+		},
+	) {
+		if err := option(o); err != nil {
+			return nil, fmt.Errorf("cannot create certificate manager: %w", err)
+		}
+	}
+
+	m := &CertificateManager{
+		pairs:          o.Pairs,
+		reloadInterval: o.ReloadInterval,
+		logger:         o.Logger,
+	}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *CertificateManager) readVersions() ([]fileVersion, error) {
+	versions := make([]fileVersion, 0, len(m.pairs)*2)
+	for _, pair := range m.pairs {
+		for _, file := range []string{pair.CertificateFile, pair.KeyFile} {
+			info, err := os.Stat(file)
+			if err != nil {
+				return nil, err
+			}
+			versions = append(versions, fileVersion{
+				ModTime: info.ModTime(),
+				Size:    info.Size(),
+			})
+		}
+	}
+	return versions, nil
+}
+
+// Reload loads all certificate key pairs. Previously loaded certificates remain in use if any of the pairs fail to load.
+func (m *CertificateManager) Reload() error {
+	versions, err := m.readVersions()
+	if err != nil {
+		return fmt.Errorf("cannot reload TLS certificates: %w", err)
+	}
+	certificates := make([]*tls.Certificate, 0, len(m.pairs))
+	byName := make(map[string]*tls.Certificate)
+	for _, pair := range m.pairs {
+		certificate, err := tls.LoadX509KeyPair(pair.CertificateFile, pair.KeyFile)
+		if err != nil {
+			return fmt.Errorf("cannot reload TLS certificate %q: %w", pair.CertificateFile, err)
+		}
+		if certificate.Leaf == nil {
+			if certificate.Leaf, err = x509.ParseCertificate(certificate.Certificate[0]); err != nil {
+				return fmt.Errorf("cannot parse TLS certificate %q: %w", pair.CertificateFile, err)
+			}
+		}
+		certificates = append(certificates, &certificate)
+		for _, name := range certificate.Leaf.DNSNames {
+			name = strings.ToLower(name)
+			if _, ok := byName[name]; !ok {
+				byName[name] = &certificate
+			}
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.versions = versions
+	m.certificates = certificates
+	m.byName = byName
+	return nil
+}
+
+// GetCertificate selects a certificate by exact server name, then by wildcard name, and falls back on the first certificate. Use as [tls.Config.GetCertificate].
+func (m *CertificateManager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	if certificate, ok := m.byName[name]; ok {
+		return certificate, nil
+	}
+	if _, parent, ok := strings.Cut(name, "."); ok {
+		if certificate, ok := m.byName["*."+parent]; ok {
+			return certificate, nil
+		}
+	}
+	return m.certificates[0], nil
+}
+
+func (m *CertificateManager) changed() bool {
+	versions, err := m.readVersions()
+	if err != nil {
+		return true // let reload report the error
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for i, version := range versions {
+		if version != m.versions[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch reloads certificates when their files change or the process receives SIGHUP until the context is done. Reload failures are logged and the previous certificates remain in use. [Run] watches the managers passed to it automatically.
+func (m *CertificateManager) Watch(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	ticker := time.NewTicker(m.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+		case <-ticker.C:
+			if !m.changed() {
+				continue
+			}
+		}
+		if err := m.Reload(); err != nil {
+			m.logger.Error("failed to reload TLS certificates", slog.Any("error", err))
+		} else {
+			m.logger.Info("reloaded TLS certificates")
+		}
+	}
+}
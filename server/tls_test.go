@@ -0,0 +1,249 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCertificate struct {
+	CertificateFile string
+	KeyFile         string
+	Certificate     *x509.Certificate
+	Key             crypto.Signer
+}
+
+// newTestCertificate writes a certificate signed by the parent or a self-signed one if parent is nil.
+func newTestCertificate(t *testing.T, name string, parent *testCertificate, template *x509.Certificate) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = serial
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerCertificate := crypto.Signer(key), template
+	if parent != nil {
+		signer, signerCertificate = parent.Key, parent.Certificate
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCertificate, key.Public(), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	result := &testCertificate{
+		CertificateFile: filepath.Join(dir, "cert.pem"),
+		KeyFile:         filepath.Join(dir, "key.pem"),
+		Certificate:     certificate,
+		Key:             key,
+	}
+	if err = os.WriteFile(result.CertificateFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(result.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func newTestServerCertificate(t *testing.T, names ...string) *testCertificate {
+	return newTestCertificate(t, names[0], nil, &x509.Certificate{
+		DNSNames:    names,
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+}
+
+func newTestClientTLSConfig(trusted *testCertificate, certificates ...tls.Certificate) *tls.Config {
+	roots := x509.NewCertPool()
+	roots.AddCert(trusted.Certificate)
+	return &tls.Config{
+		RootCAs:      roots,
+		Certificates: certificates,
+	}
+}
+
+func TestCertificateManagerServerNameIndication(t *testing.T) {
+	first := newTestServerCertificate(t, "a.test")
+	second := newTestServerCertificate(t, "*.b.test")
+	m, err := NewCertificateManager(
+		WithCertificateKeyPair(first.CertificateFile, first.KeyFile),
+		WithCertificateKeyPair(second.CertificateFile, second.KeyFile),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for serverName, expected := range map[string]*testCertificate{
+		"a.test":     first,
+		"A.test.":    first,
+		"x.b.test":   second,
+		"unknown":    first,
+		"x.y.b.test": first,
+	} {
+		certificate, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: serverName})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !certificate.Leaf.Equal(expected.Certificate) {
+			t.Errorf("server name %q selected an unexpected certificate: %v", serverName, certificate.Leaf.DNSNames)
+		}
+	}
+}
+
+func TestCertificateManagerReload(t *testing.T) {
+	original := newTestServerCertificate(t, "a.test")
+	m, err := NewCertificateManager(
+		WithCertificateKeyPair(original.CertificateFile, original.KeyFile),
+		WithCertificateReloadInterval(time.Millisecond*10),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Watch(ctx)
+
+	rotated := newTestServerCertificate(t, "a.test")
+	for _, file := range [][2]string{
+		{rotated.CertificateFile, original.CertificateFile},
+		{rotated.KeyFile, original.KeyFile},
+	} {
+		if err = os.Rename(file[0], file[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		certificate, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "a.test"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if certificate.Leaf.Equal(rotated.Certificate) {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	t.Fatal("certificate was not reloaded after its files changed")
+}
+
+func TestCertificateManagerKeepsCertificatesOnFailedReload(t *testing.T) {
+	original := newTestServerCertificate(t, "a.test")
+	m, err := NewCertificateManager(WithCertificateKeyPair(original.CertificateFile, original.KeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(original.KeyFile, []byte("broken"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Reload(); err == nil {
+		t.Fatal("reloading a broken key should fail")
+	}
+	certificate, err := m.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !certificate.Leaf.Equal(original.Certificate) {
+		t.Fatal("previous certificate was not retained")
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	serverCertificate := newTestServerCertificate(t, "localhost")
+	authority := newTestCertificate(t, "Test Authority", nil, &x509.Certificate{
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	})
+	clientCertificate := newTestCertificate(t, "client", authority, &x509.Certificate{
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+
+	m, err := NewCertificateManager(WithCertificateKeyPair(serverCertificate.CertificateFile, serverCertificate.KeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errChannel := make(chan error)
+	go func() {
+		errChannel <- Run(
+			ctx,
+			WithListener(ln),
+			WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})),
+			WithCertificateManager(m, WithClientCertificateAuthority(authority.CertificateFile)),
+		)
+	}()
+	time.Sleep(time.Millisecond * 50)
+
+	newClient := func(certificates ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{
+			TLSClientConfig: newTestClientTLSConfig(serverCertificate, certificates...),
+		}}
+	}
+	url := "https://" + ln.Addr().String()
+
+	if resp, err := newClient().Get(url); err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("client without a certificate was accepted")
+	}
+
+	keyPair, err := tls.LoadX509KeyPair(clientCertificate.CertificateFile, clientCertificate.KeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := newClient(keyPair).Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatal("unexpected status code:", resp.StatusCode)
+	}
+	if resp.TLS.Version < tls.VersionTLS12 {
+		t.Fatal("negotiated TLS version is too low")
+	}
+
+	cancel()
+	if err = <-errChannel; err != nil {
+		t.Fatal(err)
+	}
+}
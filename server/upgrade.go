@@ -0,0 +1,32 @@
+package server
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+const DefaultUpgradeTimeout = time.Minute
+
+// WithUpgradeSignal replaces the running process with a new copy of its executable without dropping connections when the process receives the signal, which is conventionally SIGUSR2. Listener sockets are passed to the new process using the LISTEN_FDS protocol, so [WithAddress], [WithNamedAddress], [WithHTTPSRedirect], and systemd socket activation options pick them up by name instead of binding new ones. Once the new process starts serving, this process turns not ready, drains, runs shutdown hooks, and [Run] returns without an error. If the new process fails to start serving within [DefaultUpgradeTimeout], it is killed and this process keeps serving.
+//
+//	err := server.Run(ctx,
+//	  server.WithHandler(application),
+//	  server.WithUpgradeSignal(syscall.SIGUSR2),
+//	)
+//
+//	$ go build -o /usr/local/bin/myapp && kill -USR2 $(pidof myapp)
+//
+// Only listeners that expose their file descriptors, like TCP and unix socket listeners, can be passed. Upgrades are not supported on Windows or together with [WithExperimentalHTTP3].
+func WithUpgradeSignal(signal os.Signal) Option {
+	return func(o *options) error {
+		if o.UpgradeSignal != nil {
+			return errors.New("upgrade signal is already set")
+		}
+		if signal == nil {
+			return errors.New("cannot use a <nil> upgrade signal")
+		}
+		o.UpgradeSignal = signal
+		return nil
+	}
+}
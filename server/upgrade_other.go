@@ -0,0 +1,17 @@
+//go:build !unix
+
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+func upgrade(context.Context, []namedListener, *slog.Logger) error {
+	return errors.New("binary upgrades are not supported on this platform")
+}
+
+func notifyUpgradeParent() error {
+	return nil
+}
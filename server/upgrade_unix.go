@@ -0,0 +1,130 @@
+//go:build unix
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// upgrade starts a new copy of the executable with inherited listeners and waits for it to start serving.
+func upgrade(ctx context.Context, listeners []namedListener, logger *slog.Logger) (err error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate executable: %w", err)
+	}
+
+	files := make([]*os.File, 0, len(listeners)+1)
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+	names := make([]string, 0, len(listeners))
+	for _, l := range listeners {
+		filer, ok := l.Listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			return fmt.Errorf("listener %q does not expose its file descriptor", l.Name)
+		}
+		file, err := filer.File()
+		if err != nil {
+			return fmt.Errorf("cannot duplicate listener %q: %w", l.Name, err)
+		}
+		files = append(files, file)
+		names = append(names, l.Name)
+	}
+
+	ready, notify, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("cannot create readiness pipe: %w", err)
+	}
+	defer ready.Close()
+	files = append(files, notify)
+
+	environment := make([]string, 0, len(os.Environ())+4)
+	for _, variable := range os.Environ() {
+		switch name, _, _ := strings.Cut(variable, "="); name {
+		case "LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES",
+			upgradeParentEnvironmentVariable, upgradeReadyEnvironmentVariable:
+		default:
+			environment = append(environment, variable)
+		}
+	}
+	environment = append(environment,
+		"LISTEN_FDS="+strconv.Itoa(len(listeners)),
+		"LISTEN_FDNAMES="+strings.Join(names, ":"),
+		upgradeParentEnvironmentVariable+"="+strconv.Itoa(os.Getpid()),
+		upgradeReadyEnvironmentVariable+"="+strconv.Itoa(3+len(listeners)),
+	)
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Env = environment
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("cannot start upgraded process: %w", err)
+	}
+	logger.Info("started upgraded process", slog.Int("pid", cmd.Process.Pid))
+
+	signaled := make(chan error, 1)
+	go func() {
+		_ = notify.Close() // child holds its own copy
+		b, err := io.ReadAll(ready)
+		if err == nil && len(b) == 0 {
+			err = errors.New("upgraded process exited before it started serving")
+		}
+		signaled <- err
+	}()
+
+	timeout := time.NewTimer(DefaultUpgradeTimeout)
+	defer timeout.Stop()
+	select {
+	case err = <-signaled:
+	case <-timeout.C:
+		err = errors.New("upgraded process did not start serving in time")
+	case <-ctx.Done():
+		err = context.Cause(ctx)
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		go cmd.Wait() // reap
+		return err
+	}
+
+	for _, l := range listeners {
+		if unix, ok := l.Listener.(*net.UnixListener); ok {
+			unix.SetUnlinkOnClose(false) // socket file now belongs to the upgraded process
+		}
+	}
+	return cmd.Process.Release()
+}
+
+// notifyUpgradeParent tells the parent process that started this one during a binary upgrade that it is now serving requests.
+func notifyUpgradeParent() error {
+	descriptor := os.Getenv(upgradeReadyEnvironmentVariable)
+	if descriptor == "" {
+		return nil
+	}
+	_ = os.Unsetenv(upgradeReadyEnvironmentVariable)
+	fd, err := strconv.Atoi(descriptor)
+	if err != nil || fd < 3 {
+		return fmt.Errorf("invalid upgrade readiness file descriptor %q", descriptor)
+	}
+	file := os.NewFile(uintptr(fd), "upgrade-ready")
+	if _, err = file.Write([]byte("ready\n")); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("cannot notify parent process: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,90 @@
+//go:build unix
+
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const upgradeTestListenerName = "upgrade-test"
+
+// TestMain acts as the upgraded process when the test binary is started by [TestBinaryUpgrade].
+func TestMain(m *testing.M) {
+	if os.Getenv(upgradeParentEnvironmentVariable) == "" {
+		os.Exit(m.Run())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+	err := Run(
+		ctx,
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("child"))
+		})),
+		WithNamedAddress(upgradeTestListenerName, "tcp", "127.0.0.1:0"),
+	)
+	if err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestBinaryUpgrade(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errChannel := make(chan error)
+	go func() {
+		errChannel <- Run(
+			context.Background(),
+			WithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("parent"))
+			})),
+			WithNamedListener(upgradeTestListenerName, ln),
+			WithUpgradeSignal(syscall.SIGUSR2),
+		)
+	}()
+	time.Sleep(time.Millisecond * 50)
+
+	get := func() string {
+		client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+		resp, err := client.Get("http://" + ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	if body := get(); body != "parent" {
+		t.Fatal("unexpected response before upgrade:", body)
+	}
+
+	if err = syscall.Kill(os.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-errChannel:
+		if err != nil {
+			t.Fatal("parent did not shut down cleanly:", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("parent did not shut down after upgrade")
+	}
+
+	if body := get(); body != "child" {
+		t.Fatal("upgraded process is not serving:", body)
+	}
+}
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/dkotik/oakhttp/config"
+)
+
+var configurationFields = []config.Field[options]{
+	config.Duration("value_retention", WithValueRetentionFor),
+	config.Duration("removal_frequency", WithRemovalFrequencyOf),
+	config.Int("maximum_value_count", WithMaximumValueCount),
+}
+
+// WithEnvironment loads settings from environment variables with the given prefix: VALUE_RETENTION, REMOVAL_FREQUENCY, and MAXIMUM_VALUE_COUNT.
+func WithEnvironment(prefix string) Option {
+	return func(o *options) error {
+		if err := config.Apply(o, config.Environment(prefix), configurationFields...); err != nil {
+			return fmt.Errorf("cannot configure store from environment: %w", err)
+		}
+		return nil
+	}
+}
+
+// WithConfigFile loads the same settings as [WithEnvironment] from a JSON configuration file with lower case field names, like "value_retention". Unknown fields are rejected.
+func WithConfigFile(path string) Option {
+	return func(o *options) error {
+		source, err := config.File(path)
+		if err == nil {
+			err = config.Apply(o, source, configurationFields...)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot configure store from file: %w", err)
+		}
+		return nil
+	}
+}
@@ -152,6 +152,13 @@ func (m *mapKeyKeyValue) Delete(ctx context.Context, key1, key2 []byte) error {
 	return nil
 }
 
+// Len returns the number of stored values, including expired values that were not removed yet.
+func (m *mapKeyKeyValue) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.total
+}
+
 func (m *mapKeyKeyValue) RemoveExpired(ctx context.Context, cutoff time.Time) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -123,6 +123,13 @@ func (m *mapKeyValue) Delete(ctx context.Context, key []byte) error {
 	return nil
 }
 
+// Len returns the number of stored values, including expired values that were not removed yet.
+func (m *mapKeyValue) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.tokens)
+}
+
 func (m *mapKeyValue) RemoveExpired(ctx context.Context, cutoff time.Time) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -27,6 +27,11 @@ type KeyKeyValue interface {
 	Delete(ctx context.Context, key1, key2 []byte) error
 }
 
+// Sized is implemented by stores that can report how many values they hold.
+type Sized interface {
+	Len() int
+}
+
 type expiringValue struct {
 	Data    []byte
 	Expires time.Time
@@ -3,8 +3,10 @@ package oakratelimiter
 import (
 	"context"
 	"errors"
-	"net"
+	"fmt"
 	"net/http"
+
+	"github.com/dkotik/oakhttp"
 )
 
 // SkipTagger is a sentinel error used to indicate
@@ -34,14 +36,14 @@ func NewRequestTaggerFromContextTagger(t ContextTagger) Tagger {
 	}
 }
 
-// NewIPAddressTagger tags requests by client IP address.
+// NewIPAddressTagger tags requests by client IP address. Place it after [oakhttp.NewRealIPMiddleware] to tell apart clients behind a load balancer.
 func NewIPAddressTagger() Tagger {
 	return func(r *http.Request) (string, error) {
-		ip, _, err := net.SplitHostPort(r.RemoteAddr)
-		if err != nil {
-			return "", err
+		ip := oakhttp.ClientIP(r)
+		if !ip.IsValid() {
+			return "", fmt.Errorf("cannot determine client IP address from %q", r.RemoteAddr)
 		}
-		return ip, nil
+		return ip.String(), nil
 	}
 }
 
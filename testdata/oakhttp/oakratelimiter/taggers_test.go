@@ -0,0 +1,40 @@
+package oakratelimiter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dkotik/oakhttp"
+)
+
+func TestIPAddressTaggerUsesResolvedAddress(t *testing.T) {
+	realIP, err := oakhttp.NewRealIPMiddleware(oakhttp.WithTrustedProxies("10.0.0.0/8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagger := NewIPAddressTagger()
+
+	var tag string
+	h := realIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tag, err = tagger(r); err != nil {
+			t.Fatal(err)
+		}
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:4000"
+	r.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.2")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if tag != "203.0.113.7" {
+		t.Fatalf("request from behind a trusted proxy was tagged as %q", tag)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "198.51.100.1:4000"
+	r.Header.Set("X-Forwarded-For", "203.0.113.7")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if tag != "198.51.100.1" {
+		t.Fatalf("forwarded address from an untrusted peer was tagged as %q", tag)
+	}
+}
@@ -0,0 +1,54 @@
+package oakhttp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/dkotik/oakhttp/internal/tracecontext"
+)
+
+// W3C Trace Context headers: https://www.w3.org/TR/trace-context/
+const (
+	TraceParentHeader = tracecontext.TraceParentHeader
+	TraceStateHeader  = tracecontext.TraceStateHeader
+)
+
+// TraceContext is a [Traceable] that follows the W3C Trace Context specification. It continues a trace started by the caller and identifies the current request by its own span.
+type TraceContext = tracecontext.TraceContext
+
+// ParseTraceParent reads trace and parent span identifiers from a traceparent header value.
+func ParseTraceParent(value string) (traceID, parentSpanID, flags string, err error) {
+	return tracecontext.ParseTraceParent(value)
+}
+
+// NewTraceContext continues the trace described by traceparent and tracestate header values with a new span. A new trace is started if the traceparent value is empty or invalid.
+func NewTraceContext(traceParent, traceState string) *TraceContext {
+	return tracecontext.New(traceParent, traceState)
+}
+
+// TraceContextFromContext recovers the [TraceContext] set by [NewTraceContextMiddleware].
+func TraceContextFromContext(ctx context.Context) (*TraceContext, bool) {
+	return tracecontext.FromContext(ctx)
+}
+
+// NewTraceContextMiddleware continues W3C traces from the traceparent and tracestate request headers, or starts new ones. The resulting [TraceContext] is stored in the request context, so that [TraceIDFromContext] and the [NewTracingHandler] logger report the caller's trace ID. Trace headers identifying the current span are echoed on the response.
+func NewTraceContextMiddleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		if next == nil {
+			panic("cannot use a <nil> handler")
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t := NewTraceContext(
+				r.Header.Get(TraceParentHeader),
+				strings.Join(r.Header.Values(TraceStateHeader), ","),
+			)
+			header := w.Header()
+			header.Set(TraceParentHeader, t.TraceParent())
+			if t.State != "" {
+				header.Set(TraceStateHeader, t.State)
+			}
+			next.ServeHTTP(w, r.WithContext(ContextWithTracing(r.Context(), t)))
+		})
+	}
+}
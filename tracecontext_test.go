@@ -0,0 +1,76 @@
+package oakhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+var lowerHex = regexp.MustCompile(`^[0-9a-f]+$`)
+
+func isLowerHex(s string, length int) bool {
+	return len(s) == length && lowerHex.MatchString(s)
+}
+
+func TestTraceContextMiddleware(t *testing.T) {
+	var recovered *TraceContext
+	h := NewTraceContextMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ok bool
+		recovered, ok = TraceContextFromContext(r.Context())
+		if !ok {
+			t.Fatal("trace context is missing")
+		}
+		if TraceIDFromContext(r.Context()) != recovered.TraceID {
+			t.Fatal("trace ID does not match trace context")
+		}
+	}))
+
+	t.Run("continue", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		r.Header.Set(TraceStateHeader, "congo=t61rcWkgMzE")
+		h.ServeHTTP(w, r)
+
+		if recovered.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Fatal("trace ID was not continued:", recovered.TraceID)
+		}
+		if recovered.ParentSpanID != "00f067aa0ba902b7" {
+			t.Fatal("parent span ID was not recorded:", recovered.ParentSpanID)
+		}
+		if recovered.SpanID == recovered.ParentSpanID || !isLowerHex(recovered.SpanID, 16) {
+			t.Fatal("invalid child span ID:", recovered.SpanID)
+		}
+		if !recovered.IsSampled() {
+			t.Fatal("sampled flag was lost")
+		}
+		if header := w.Header().Get(TraceParentHeader); header != recovered.TraceParent() {
+			t.Fatal("traceparent was not echoed:", header)
+		}
+		if header := w.Header().Get(TraceStateHeader); header != "congo=t61rcWkgMzE" {
+			t.Fatal("tracestate was not echoed:", header)
+		}
+	})
+
+	for _, invalid := range []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		t.Run("restart "+invalid, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(TraceParentHeader, invalid)
+			h.ServeHTTP(httptest.NewRecorder(), r)
+			if recovered.TraceID == "4bf92f3577b34da6a3ce929d0e0e4736" || !isLowerHex(recovered.TraceID, 32) {
+				t.Fatal("invalid trace was not restarted:", recovered.TraceID)
+			}
+			if recovered.ParentSpanID != "" {
+				t.Fatal("new trace has a parent span:", recovered.ParentSpanID)
+			}
+		})
+	}
+}
@@ -6,9 +6,9 @@ import (
 	"log/slog"
 	"runtime/debug"
 	"sync"
-)
 
-type contextKey struct{}
+	"github.com/dkotik/oakhttp/internal/tracecontext"
+)
 
 type Traceable interface {
 	GetTraceID() string
@@ -56,11 +56,11 @@ func ContextWithTraceID(parent context.Context, ID string) context.Context {
 }
 
 func ContextWithTracing(parent context.Context, t Traceable) context.Context {
-	return context.WithValue(parent, contextKey{}, t)
+	return tracecontext.ContextWith(parent, t)
 }
 
 func TraceIDFromContext(ctx context.Context) string {
-	t, _ := ctx.Value(contextKey{}).(Traceable)
+	t, _ := tracecontext.Value(ctx).(Traceable)
 	if t == nil {
 		return ""
 	}
@@ -0,0 +1,129 @@
+package oakhttp
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/dkotik/oakhttp/internal/msg"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// LocalizableError can be rendered by [ErrorHandler] in the language of the request.
+type LocalizableError interface {
+	error
+	Localize(*i18n.Localizer) (LocalizedError, error)
+}
+
+// FieldError describes a problem with a single request field. Path identifies the field using dot notation, such as "address.street" or "items.2.quantity".
+type FieldError struct {
+	Path    string
+	Message *i18n.LocalizeConfig
+}
+
+// NewFieldError pairs a field path with a message. Template data is passed to the message during localization.
+func NewFieldError(path string, message *i18n.Message, templateData any) FieldError {
+	return FieldError{
+		Path: path,
+		Message: &i18n.LocalizeConfig{
+			DefaultMessage: message,
+			TemplateData:   templateData,
+		},
+	}
+}
+
+type LocalizedFieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError reports one or more invalid request fields with a single [http.StatusUnprocessableEntity] response.
+type ValidationError struct {
+	StatusCode    int
+	KnowledgeCode string
+	Title         *i18n.LocalizeConfig
+	Description   *i18n.LocalizeConfig
+	Message       *i18n.LocalizeConfig
+	Fields        []FieldError
+	Cause         error
+}
+
+func NewValidationError(from error, knowledgeCode string, fields ...FieldError) ValidationError {
+	return ValidationError{
+		StatusCode:    http.StatusUnprocessableEntity,
+		KnowledgeCode: knowledgeCode,
+		Title:         msg.ErrorValidationTitle,
+		Description:   msg.ErrorValidationDescription,
+		Message:       msg.ErrorValidationDescription,
+		Fields:        fields,
+		Cause:         from,
+	}
+}
+
+func (e ValidationError) GetHyperTextStatusCode() int {
+	return e.StatusCode
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.Cause
+}
+
+func (e ValidationError) Error() string {
+	b := &strings.Builder{}
+	b.WriteString(http.StatusText(e.StatusCode))
+	if len(e.Fields) > 0 {
+		b.WriteString(": invalid fields ")
+		for i, field := range e.Fields {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(field.Path)
+		}
+	}
+	if e.Cause != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Cause.Error())
+	}
+	return b.String()
+}
+
+func (e ValidationError) LogValue() slog.Value {
+	paths := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		paths[i] = field.Path
+	}
+	return slog.GroupValue(
+		slog.Int("status_code", e.StatusCode),
+		slog.String("message", e.Error()),
+		slog.Any("fields", paths),
+		slog.Any("cause", e.Cause),
+	)
+}
+
+func (e ValidationError) Localize(lc *i18n.Localizer) (localized LocalizedError, err error) {
+	localized, err = Error{
+		StatusCode:    e.StatusCode,
+		KnowledgeCode: e.KnowledgeCode,
+		Title:         e.Title,
+		Description:   e.Description,
+		Message:       e.Message,
+		Cause:         e.Cause,
+	}.Localize(lc)
+	if err != nil {
+		return localized, err
+	}
+
+	localized.Fields = make([]LocalizedFieldError, len(e.Fields))
+	for i, field := range e.Fields {
+		localized.Fields[i].Path = field.Path
+		if field.Message == nil {
+			return localized, fmt.Errorf("field %q error message is <nil>", field.Path)
+		}
+		localized.Fields[i].Message, err = lc.Localize(field.Message)
+		if err != nil {
+			return localized, fmt.Errorf("unable to localize field %q error message: %w", field.Path, err)
+		}
+	}
+	return localized, nil
+}
@@ -0,0 +1,71 @@
+package oakhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+func TestValidationErrorRendering(t *testing.T) {
+	tooShort := &i18n.Message{
+		ID:    "TestFieldTooShort",
+		Other: "Must be at least {{ .Minimum }} characters long.",
+	}
+	validationError := NewValidationError(
+		errors.New("test"),
+		"invalidForm",
+		NewFieldError("name", tooShort, map[string]any{"Minimum": 3}),
+		NewFieldError("address.street", tooShort, map[string]any{"Minimum": 5}),
+	)
+	eh := NewErrorHandler(nil, nil, nil, NewStandardErrors()...)
+
+	t.Run("json", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set("Accept", MediaTypeJSON)
+		eh.HandleError(w, r, validationError)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatal("unexpected status code:", w.Code)
+		}
+
+		var response struct {
+			Error LocalizedError `json:"error"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Error.Fields) != 2 {
+			t.Fatal("unexpected field count:", len(response.Error.Fields))
+		}
+		field := response.Error.Fields[1]
+		if field.Path != "address.street" || field.Message != "Must be at least 5 characters long." {
+			t.Fatalf("field error was not localized: %+v", field)
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		eh.HandleError(w, r, validationError)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatal("unexpected status code:", w.Code)
+		}
+		if !strings.Contains(w.Body.String(), "<strong>name</strong>: Must be at least 3 characters long.") {
+			t.Fatal("HTML page does not list the invalid field:", w.Body.String())
+		}
+	})
+}
+
+func TestValidationErrorWithoutFieldMessage(t *testing.T) {
+	for _, field := range []FieldError{{}, {Path: "name"}} {
+		_, err := NewValidationError(nil, "", field).Localize(testLocalizer)
+		if err == nil {
+			t.Fatalf("field error without a message was localized: %+v", field)
+		}
+	}
+}